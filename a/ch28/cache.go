@@ -1,5 +1,7 @@
 package cache
 
+import "strings"
+
 // Cache interface defines the contract for all cache implementations
 type Cache interface {
 	Get(key string) (value interface{}, found bool)
@@ -20,6 +22,45 @@ const (
 	FIFO
 )
 
+// Policies lists every policy understood by NewCache, in declaration order.
+var Policies = []CachePolicy{LRU, LFU, FIFO}
+
+// String returns the string representation of the policy
+func (p CachePolicy) String() string {
+	switch p {
+	case LRU:
+		return "LRU"
+	case LFU:
+		return "LFU"
+	case FIFO:
+		return "FIFO"
+	default:
+		return "Unknown"
+	}
+}
+
+// ParsePolicy returns the policy with the given (case-insensitive) name.
+func ParsePolicy(name string) (CachePolicy, bool) {
+	for _, p := range Policies {
+		if strings.EqualFold(p.String(), name) {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+// policyCache is the internal surface shared by the LRU, LFU and FIFO caches,
+// used by wrappers that manage their own view of the contents.
+type policyCache interface {
+	Cache
+	// evictOne removes the entry the policy would evict next.
+	evictOne() bool
+	// contains reports whether key is cached without counting as an access.
+	contains(key string) bool
+	// setEvictHook registers fn to be called for every evicted entry.
+	setEvictHook(fn func(key string, value interface{}))
+}
+
 // --- Common Payload for Nodes ---
 
 type cachePayload struct {
@@ -45,7 +86,11 @@ func NewCache(policy CachePolicy, capacity int) Cache {
 	}
 }
 
-// NewThreadSafeCacheWithPolicy creates a thread-safe cache with the specified policy
+// NewThreadSafeCacheWithPolicy creates a thread-safe cache with the specified policy.
+// Like the policy constructors it returns nil for a non-positive capacity.
 func NewThreadSafeCacheWithPolicy(policy CachePolicy, capacity int) Cache {
+	if capacity <= 0 {
+		return nil
+	}
 	return NewThreadSafeCache(NewCache(policy, capacity))
 }
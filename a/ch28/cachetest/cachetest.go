@@ -0,0 +1,345 @@
+// Package cachetest implements a conformance suite for cache.Cache
+// implementations.
+//
+// A test for a custom implementation typically looks like:
+//
+//	func TestMyCache(t *testing.T) {
+//		cachetest.Run(t, cache.LRU, func(capacity int) cache.Cache {
+//			return NewMyCache(capacity)
+//		})
+//	}
+package cachetest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+
+	cache "go-interview/a/ch28"
+)
+
+// Factory creates the cache under test. It may return nil for a
+// non-positive capacity.
+type Factory func(capacity int) cache.Cache
+
+// Run checks that the caches produced by factory satisfy the Cache contract
+// and evict in the order prescribed by policy.
+func Run(t *testing.T, policy cache.CachePolicy, factory Factory) {
+	t.Helper()
+	RunContract(t, factory)
+	t.Run("EvictionOrder", func(t *testing.T) { testEvictionOrder(t, policy, factory) })
+	t.Run("Reference", func(t *testing.T) { testReference(t, policy, factory) })
+}
+
+// RunContract checks the Cache contract without asserting which entry is
+// evicted, for implementations that only approximate their policy.
+func RunContract(t *testing.T, factory Factory) {
+	t.Helper()
+	t.Run("Capacity", func(t *testing.T) { testCapacity(t, factory) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, factory) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, factory) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, factory) })
+	t.Run("NilValues", func(t *testing.T) { testNilValues(t, factory) })
+	t.Run("ZeroCapacity", func(t *testing.T) { testZeroCapacity(t, factory) })
+	t.Run("HitRate", func(t *testing.T) { testHitRate(t, factory) })
+}
+
+// RunConcurrent hammers the caches produced by factory from many goroutines.
+// It is only meaningful for thread-safe implementations and should be run
+// with -race.
+func RunConcurrent(t *testing.T, factory Factory) {
+	t.Helper()
+	t.Run("Concurrent", func(t *testing.T) { testConcurrent(t, factory) })
+}
+
+func isNil(c cache.Cache) bool {
+	if c == nil {
+		return true
+	}
+	v := reflect.ValueOf(c)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+func newCache(t *testing.T, factory Factory, capacity int) cache.Cache {
+	t.Helper()
+	c := factory(capacity)
+	if isNil(c) {
+		t.Fatalf("factory(%d) returned nil", capacity)
+	}
+	return c
+}
+
+func expectPresent(t *testing.T, c cache.Cache, key string, want interface{}) {
+	t.Helper()
+	got, found := c.Get(key)
+	if !found || got != want {
+		t.Errorf("Get(%q): expected (%v, true), got (%v, %v)", key, want, got, found)
+	}
+}
+
+func expectAbsent(t *testing.T, c cache.Cache, key string) {
+	t.Helper()
+	if got, found := c.Get(key); found {
+		t.Errorf("Get(%q): expected miss, got (%v, true)", key, got)
+	}
+}
+
+func expectSize(t *testing.T, c cache.Cache, want int) {
+	t.Helper()
+	if got := c.Size(); got != want {
+		t.Errorf("Size(): expected %d, got %d", want, got)
+	}
+}
+
+func testCapacity(t *testing.T, factory Factory) {
+	for _, capacity := range []int{1, 2, 10} {
+		c := newCache(t, factory, capacity)
+		if c.Capacity() != capacity {
+			t.Errorf("Capacity(): expected %d, got %d", capacity, c.Capacity())
+		}
+		expectSize(t, c, 0)
+
+		for i := 0; i < capacity*3; i++ {
+			c.Put(fmt.Sprintf("key-%d", i), i)
+			if c.Size() > capacity {
+				t.Fatalf("Size() %d exceeds capacity %d after %d puts", c.Size(), capacity, i+1)
+			}
+		}
+		expectSize(t, c, capacity)
+	}
+}
+
+func testUpdate(t *testing.T, factory Factory) {
+	c := newCache(t, factory, 2)
+	c.Put("a", 1)
+	c.Put("a", 2)
+	expectPresent(t, c, "a", 2)
+	expectSize(t, c, 1)
+}
+
+func testDelete(t *testing.T, factory Factory) {
+	c := newCache(t, factory, 2)
+	c.Put("a", 1)
+	c.Put("b", 2)
+
+	if !c.Delete("a") {
+		t.Error("Delete of existing key returned false")
+	}
+	if c.Delete("a") {
+		t.Error("Second Delete of the same key returned true")
+	}
+	if c.Delete("missing") {
+		t.Error("Delete of missing key returned true")
+	}
+	expectAbsent(t, c, "a")
+	expectSize(t, c, 1)
+
+	// The freed slot must be reusable without evicting the survivor.
+	c.Put("c", 3)
+	expectPresent(t, c, "b", 2)
+	expectPresent(t, c, "c", 3)
+	expectSize(t, c, 2)
+}
+
+func testClear(t *testing.T, factory Factory) {
+	c := newCache(t, factory, 3)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Clear()
+
+	expectSize(t, c, 0)
+	expectAbsent(t, c, "a")
+	expectAbsent(t, c, "b")
+
+	// A cleared cache keeps its capacity and remains usable.
+	if c.Capacity() != 3 {
+		t.Errorf("Capacity() after Clear: expected 3, got %d", c.Capacity())
+	}
+	for _, key := range []string{"x", "y", "z"} {
+		c.Put(key, key)
+	}
+	expectSize(t, c, 3)
+	expectPresent(t, c, "x", "x")
+}
+
+func testNilValues(t *testing.T, factory Factory) {
+	c := newCache(t, factory, 2)
+	c.Put("nil", nil)
+	expectPresent(t, c, "nil", nil)
+	expectSize(t, c, 1)
+
+	c.Put("", "empty")
+	expectPresent(t, c, "", "empty")
+}
+
+func testZeroCapacity(t *testing.T, factory Factory) {
+	for _, capacity := range []int{0, -1} {
+		c := factory(capacity)
+		if isNil(c) {
+			continue // Rejecting the capacity outright is allowed.
+		}
+		c.Put("a", 1)
+		expectAbsent(t, c, "a")
+		expectSize(t, c, 0)
+	}
+}
+
+func testHitRate(t *testing.T, factory Factory) {
+	c := newCache(t, factory, 2)
+	if c.HitRate() != 0 {
+		t.Errorf("HitRate() with no lookups: expected 0, got %f", c.HitRate())
+	}
+
+	c.Put("a", 1)
+	c.Get("a")       // hit
+	c.Get("missing") // miss
+	c.Get("a")       // hit
+	c.Put("b", 2)    // writes don't count
+
+	if got, want := c.HitRate(), 2.0/3.0; math.Abs(got-want) > 1e-9 {
+		t.Errorf("HitRate(): expected %f, got %f", want, got)
+	}
+}
+
+func testEvictionOrder(t *testing.T, policy cache.CachePolicy, factory Factory) {
+	c := newCache(t, factory, 2)
+	switch policy {
+	case cache.LRU:
+		c.Put("a", 1)
+		c.Put("b", 2)
+		c.Get("a")
+		c.Put("c", 3)
+		expectAbsent(t, c, "b")
+		expectPresent(t, c, "a", 1)
+
+		// Updating a key counts as a use.
+		c.Put("a", 10)
+		c.Put("d", 4)
+		expectAbsent(t, c, "c")
+		expectPresent(t, c, "a", 10)
+	case cache.LFU:
+		c.Put("a", 1)
+		c.Put("b", 2)
+		c.Get("a")
+		c.Get("a")
+		c.Put("c", 3)
+		expectAbsent(t, c, "b")
+		expectPresent(t, c, "a", 1)
+
+		// Equal frequencies fall back to least recently used.
+		tie := newCache(t, factory, 2)
+		tie.Put("a", 1)
+		tie.Put("b", 2)
+		tie.Put("c", 3)
+		expectAbsent(t, tie, "a")
+		expectPresent(t, tie, "b", 2)
+	case cache.FIFO:
+		c.Put("a", 1)
+		c.Put("b", 2)
+		c.Get("a")
+		c.Put("a", 10) // neither reads nor updates change insertion order
+		c.Put("c", 3)
+		expectAbsent(t, c, "a")
+		expectPresent(t, c, "b", 2)
+		expectPresent(t, c, "c", 3)
+	default:
+		t.Skipf("no eviction order defined for policy %v", policy)
+	}
+}
+
+// testReference drives the cache and the reference model with the same
+// random operation sequences and requires identical observable behaviour.
+func testReference(t *testing.T, policy cache.CachePolicy, factory Factory) {
+	const (
+		seeds     = 50
+		opsPerRun = 500
+		keySpace  = 8
+	)
+
+	for seed := int64(1); seed <= seeds; seed++ {
+		rnd := rand.New(rand.NewSource(seed))
+		capacity := 1 + rnd.Intn(5)
+		got := newCache(t, factory, capacity)
+		want := NewReference(policy, capacity)
+
+		var history []string
+		fail := func(format string, args ...interface{}) {
+			t.Helper()
+			t.Fatalf("seed %d, capacity %d, after %v: %s", seed, capacity, history, fmt.Sprintf(format, args...))
+		}
+
+		for i := 0; i < opsPerRun; i++ {
+			key := fmt.Sprintf("k%d", rnd.Intn(keySpace))
+			switch op := rnd.Intn(10); {
+			case op < 5:
+				history = append(history, "get "+key)
+				gv, gf := got.Get(key)
+				wv, wf := want.Get(key)
+				if gv != wv || gf != wf {
+					fail("Get(%q) = (%v, %v), reference (%v, %v)", key, gv, gf, wv, wf)
+				}
+			case op < 9:
+				history = append(history, fmt.Sprintf("put %s=%d", key, i))
+				got.Put(key, i)
+				want.Put(key, i)
+			default:
+				history = append(history, "delete "+key)
+				if g, w := got.Delete(key), want.Delete(key); g != w {
+					fail("Delete(%q) = %v, reference %v", key, g, w)
+				}
+			}
+
+			if g, w := got.Size(), want.Size(); g != w {
+				fail("Size() = %d, reference %d", g, w)
+			}
+		}
+
+		if g, w := got.HitRate(), want.HitRate(); math.Abs(g-w) > 1e-9 {
+			fail("HitRate() = %f, reference %f", g, w)
+		}
+	}
+}
+
+func testConcurrent(t *testing.T, factory Factory) {
+	const (
+		capacity      = 64
+		numGoroutines = 16
+		numOperations = 2000
+	)
+
+	c := newCache(t, factory, capacity)
+	var wg sync.WaitGroup
+	for g := 0; g < numGoroutines; g++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(int64(id)))
+			for i := 0; i < numOperations; i++ {
+				key := fmt.Sprintf("key-%d", rnd.Intn(capacity*2))
+				switch rnd.Intn(10) {
+				case 0:
+					c.Delete(key)
+				case 1, 2, 3:
+					c.Put(key, i)
+				default:
+					c.Get(key)
+				}
+				if i%500 == 0 {
+					c.Size()
+					c.HitRate()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if size := c.Size(); size < 0 || size > capacity {
+		t.Errorf("Size() %d out of range [0, %d] after concurrent access", size, capacity)
+	}
+	if rate := c.HitRate(); rate < 0 || rate > 1 {
+		t.Errorf("HitRate() %f out of range after concurrent access", rate)
+	}
+}
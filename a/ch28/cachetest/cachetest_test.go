@@ -0,0 +1,70 @@
+package cachetest
+
+import (
+	"testing"
+
+	cache "go-interview/a/ch28"
+)
+
+func TestPolicies(t *testing.T) {
+	for _, policy := range cache.Policies {
+		t.Run(policy.String(), func(t *testing.T) {
+			Run(t, policy, func(capacity int) cache.Cache {
+				return cache.NewCache(policy, capacity)
+			})
+		})
+	}
+}
+
+func TestThreadSafePolicies(t *testing.T) {
+	for _, policy := range cache.Policies {
+		t.Run(policy.String(), func(t *testing.T) {
+			factory := func(capacity int) cache.Cache {
+				return cache.NewThreadSafeCacheWithPolicy(policy, capacity)
+			}
+			Run(t, policy, factory)
+			RunConcurrent(t, factory)
+		})
+	}
+}
+
+func TestPartitionedNamespace(t *testing.T) {
+	for _, policy := range cache.Policies {
+		t.Run(policy.String(), func(t *testing.T) {
+			factory := func(capacity int) cache.Cache {
+				partitioned := cache.NewPartitionedCache(capacity, capacity, policy)
+				if partitioned == nil {
+					return nil
+				}
+				return partitioned.Namespace("tenant")
+			}
+			Run(t, policy, factory)
+			RunConcurrent(t, factory)
+		})
+	}
+}
+
+// ConcurrentCache replays reads into its policy out of order, so eviction
+// order is not checked.
+func TestConcurrentCache(t *testing.T) {
+	for _, policy := range cache.Policies {
+		t.Run(policy.String(), func(t *testing.T) {
+			factory := func(capacity int) cache.Cache {
+				return cache.NewConcurrentCache(policy, capacity)
+			}
+			RunContract(t, factory)
+			RunConcurrent(t, factory)
+		})
+	}
+}
+
+// The reference model must pass its own suite.
+func TestReference(t *testing.T) {
+	for _, policy := range cache.Policies {
+		t.Run(policy.String(), func(t *testing.T) {
+			Run(t, policy, func(capacity int) cache.Cache {
+				return NewReference(policy, capacity)
+			})
+		})
+	}
+}
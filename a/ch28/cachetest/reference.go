@@ -0,0 +1,125 @@
+package cachetest
+
+import cache "go-interview/a/ch28"
+
+// reference is a deliberately simple O(n) cache used as an oracle. Entries
+// live in a slice and every eviction decision is a linear scan, so the
+// behaviour of each policy is easy to read off the code.
+type reference struct {
+	policy   cache.CachePolicy
+	capacity int
+	entries  []*refEntry
+	tick     uint64
+	hits     uint64
+	misses   uint64
+}
+
+type refEntry struct {
+	key      string
+	value    interface{}
+	freq     int
+	inserted uint64 // tick of insertion, used by FIFO
+	touched  uint64 // tick of last access, used by LRU and LFU tie-breaking
+}
+
+// NewReference returns a slow but obviously correct cache with the given
+// eviction policy. It returns nil for a non-positive capacity, matching the
+// constructors in package cache.
+func NewReference(policy cache.CachePolicy, capacity int) cache.Cache {
+	if capacity <= 0 {
+		return nil
+	}
+	return &reference{policy: policy, capacity: capacity}
+}
+
+func (r *reference) find(key string) int {
+	for i, e := range r.entries {
+		if e.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *reference) touch(e *refEntry) {
+	r.tick++
+	e.freq++
+	e.touched = r.tick
+}
+
+func (r *reference) Get(key string) (interface{}, bool) {
+	i := r.find(key)
+	if i < 0 {
+		r.misses++
+		return nil, false
+	}
+	r.hits++
+	r.touch(r.entries[i])
+	return r.entries[i].value, true
+}
+
+func (r *reference) Put(key string, value interface{}) {
+	if i := r.find(key); i >= 0 {
+		r.entries[i].value = value
+		r.touch(r.entries[i])
+		return
+	}
+	if len(r.entries) >= r.capacity {
+		r.evict()
+	}
+	r.tick++
+	r.entries = append(r.entries, &refEntry{key: key, value: value, freq: 1, inserted: r.tick, touched: r.tick})
+}
+
+// evict removes the victim chosen by the policy:
+//   - LRU: the least recently touched entry
+//   - LFU: the lowest frequency, ties broken by least recently touched
+//   - FIFO: the earliest inserted entry
+func (r *reference) evict() {
+	victim := 0
+	for i, e := range r.entries {
+		v := r.entries[victim]
+		switch r.policy {
+		case cache.LFU:
+			if e.freq < v.freq || (e.freq == v.freq && e.touched < v.touched) {
+				victim = i
+			}
+		case cache.FIFO:
+			if e.inserted < v.inserted {
+				victim = i
+			}
+		default:
+			if e.touched < v.touched {
+				victim = i
+			}
+		}
+	}
+	r.entries = append(r.entries[:victim], r.entries[victim+1:]...)
+}
+
+func (r *reference) Delete(key string) bool {
+	i := r.find(key)
+	if i < 0 {
+		return false
+	}
+	r.entries = append(r.entries[:i], r.entries[i+1:]...)
+	return true
+}
+
+func (r *reference) Clear() {
+	r.entries = nil
+	r.hits = 0
+	r.misses = 0
+}
+
+func (r *reference) Size() int { return len(r.entries) }
+
+func (r *reference) Capacity() int { return r.capacity }
+
+func (r *reference) HitRate() float64 {
+	total := r.hits + r.misses
+	if total == 0 {
+		return 0.0
+	}
+	return float64(r.hits) / float64(total)
+}
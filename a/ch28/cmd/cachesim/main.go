@@ -0,0 +1,323 @@
+// Command cachesim replays an access trace against every cache policy across
+// a sweep of capacities and reports the resulting hit-rate curves.
+//
+// A trace holds one access per line, either a bare key or a CSV record of
+// op,key[,size]. A line is read as a record only when its first field is a
+// supported op (get, put or delete); any other line is taken whole as the key,
+// so keys may contain commas and quotes. A get that misses is followed by a
+// put so the trace behaves like a read-through cache. Blank lines and lines
+// starting with '#' are ignored.
+//
+// Usage:
+//
+//	cachesim -trace access.log -capacities 16,64,256 -format table
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	cache "go-interview/a/ch28"
+)
+
+// Op is the kind of access recorded in a trace.
+type Op int
+
+const (
+	OpGet Op = iota
+	OpPut
+	OpDelete
+)
+
+// Access is a single trace record.
+type Access struct {
+	Op   Op
+	Key  string
+	Size int
+}
+
+// Result holds the outcome of replaying a trace against one policy and capacity.
+type Result struct {
+	Policy      string  `json:"policy"`
+	Capacity    int     `json:"capacity"`
+	Requests    int     `json:"requests"`
+	Hits        int     `json:"hits"`
+	HitRate     float64 `json:"hit_rate"`
+	ByteHitRate float64 `json:"byte_hit_rate"`
+}
+
+// ParseTrace reads a trace in either the one-key-per-line or op,key,size form.
+func ParseTrace(r io.Reader) ([]Access, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+
+	var trace []Access
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		access, err := parseLine(text, line == 1)
+		if errors.Is(err, errHeader) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		trace = append(trace, access)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
+
+// errHeader marks the optional op,key[,size] header line
+var errHeader = errors.New("header")
+
+// parseLine reads line as an op,key[,size] record when its first field is a
+// known op, and as a bare key otherwise.
+func parseLine(line string, first bool) (Access, error) {
+	field, _, isRecord := strings.Cut(line, ",")
+	field = strings.TrimSpace(field)
+	if isRecord && first && strings.EqualFold(field, "op") {
+		return Access{}, errHeader
+	}
+	op, err := parseOp(field)
+	if !isRecord || err != nil {
+		return Access{Op: OpGet, Key: line, Size: 1}, nil
+	}
+
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	record, err := reader.Read()
+	if err != nil {
+		return Access{}, err
+	}
+	if len(record) > 3 {
+		return Access{}, fmt.Errorf("expected 2 or 3 fields, got %d", len(record))
+	}
+
+	access := Access{Op: op, Key: record[1], Size: 1}
+	if len(record) == 3 {
+		size, err := strconv.Atoi(record[2])
+		if err != nil || size < 0 {
+			return Access{}, fmt.Errorf("invalid size %q", record[2])
+		}
+		access.Size = size
+	}
+	return access, nil
+}
+
+func parseOp(s string) (Op, error) {
+	switch strings.ToLower(s) {
+	case "get", "read":
+		return OpGet, nil
+	case "put", "set", "write":
+		return OpPut, nil
+	case "delete", "del":
+		return OpDelete, nil
+	default:
+		return 0, fmt.Errorf("unknown op %q", s)
+	}
+}
+
+// Simulate replays trace against a fresh cache with the given policy and capacity.
+func Simulate(policy cache.CachePolicy, capacity int, trace []Access) Result {
+	c := cache.NewCache(policy, capacity)
+	result := Result{Policy: policy.String(), Capacity: capacity}
+
+	var bytesRequested, bytesHit int
+	for _, access := range trace {
+		switch access.Op {
+		case OpGet:
+			result.Requests++
+			bytesRequested += access.Size
+			if _, found := c.Get(access.Key); found {
+				result.Hits++
+				bytesHit += access.Size
+				continue
+			}
+			c.Put(access.Key, access.Size)
+		case OpPut:
+			c.Put(access.Key, access.Size)
+		case OpDelete:
+			c.Delete(access.Key)
+		}
+	}
+
+	if result.Requests > 0 {
+		result.HitRate = float64(result.Hits) / float64(result.Requests)
+	}
+	if bytesRequested > 0 {
+		result.ByteHitRate = float64(bytesHit) / float64(bytesRequested)
+	}
+	return result
+}
+
+// Sweep runs Simulate for every policy and capacity combination.
+func Sweep(policies []cache.CachePolicy, capacities []int, trace []Access) []Result {
+	results := make([]Result, 0, len(policies)*len(capacities))
+	for _, capacity := range capacities {
+		for _, policy := range policies {
+			results = append(results, Simulate(policy, capacity, trace))
+		}
+	}
+	return results
+}
+
+// DefaultCapacities returns powers of two up to the number of distinct keys in trace.
+func DefaultCapacities(trace []Access) []int {
+	unique := make(map[string]struct{})
+	for _, access := range trace {
+		unique[access.Key] = struct{}{}
+	}
+
+	capacities := []int{1}
+	for c := 2; c < len(unique); c *= 2 {
+		capacities = append(capacities, c)
+	}
+	if len(unique) > 1 {
+		capacities = append(capacities, len(unique))
+	}
+	return capacities
+}
+
+// WriteTable prints one row per capacity and one hit-rate column per policy.
+func WriteTable(w io.Writer, policies []cache.CachePolicy, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "capacity\t")
+	for _, p := range policies {
+		fmt.Fprintf(tw, "%s\t", p)
+	}
+	fmt.Fprintln(tw)
+
+	for i := 0; i < len(results); i += len(policies) {
+		fmt.Fprintf(tw, "%d\t", results[i].Capacity)
+		for _, r := range results[i : i+len(policies)] {
+			fmt.Fprintf(tw, "%.4f\t", r.HitRate)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// WriteCSV prints one record per policy and capacity.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"policy", "capacity", "requests", "hits", "hit_rate", "byte_hit_rate"})
+	for _, r := range results {
+		cw.Write([]string{
+			r.Policy,
+			strconv.Itoa(r.Capacity),
+			strconv.Itoa(r.Requests),
+			strconv.Itoa(r.Hits),
+			strconv.FormatFloat(r.HitRate, 'f', 6, 64),
+			strconv.FormatFloat(r.ByteHitRate, 'f', 6, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON prints results as an indented JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+func parseCapacities(s string) ([]int, error) {
+	var capacities []int
+	for _, field := range strings.Split(s, ",") {
+		c, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || c <= 0 {
+			return nil, fmt.Errorf("invalid capacity %q", field)
+		}
+		capacities = append(capacities, c)
+	}
+	return capacities, nil
+}
+
+func parsePolicies(s string) ([]cache.CachePolicy, error) {
+	if s == "" {
+		return cache.Policies, nil
+	}
+	var policies []cache.CachePolicy
+	for _, field := range strings.Split(s, ",") {
+		p, ok := cache.ParsePolicy(strings.TrimSpace(field))
+		if !ok {
+			return nil, fmt.Errorf("unknown policy %q", field)
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("cachesim", flag.ContinueOnError)
+	tracePath := fs.String("trace", "-", "trace file to replay, or - for stdin")
+	capacityList := fs.String("capacities", "", "comma-separated capacities (default: powers of two up to the key count)")
+	policyList := fs.String("policies", "", "comma-separated policies (default: all)")
+	format := fs.String("format", "table", "output format: table, csv or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := stdin
+	if *tracePath != "-" {
+		f, err := os.Open(*tracePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	trace, err := ParseTrace(input)
+	if err != nil {
+		return fmt.Errorf("parse trace: %w", err)
+	}
+
+	policies, err := parsePolicies(*policyList)
+	if err != nil {
+		return err
+	}
+
+	capacities := DefaultCapacities(trace)
+	if *capacityList != "" {
+		if capacities, err = parseCapacities(*capacityList); err != nil {
+			return err
+		}
+	}
+
+	results := Sweep(policies, capacities, trace)
+	switch *format {
+	case "table":
+		return WriteTable(stdout, policies, results)
+	case "csv":
+		return WriteCSV(stdout, results)
+	case "json":
+		return WriteJSON(stdout, results)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "cachesim:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	cache "go-interview/a/ch28"
+)
+
+func TestParseTrace(t *testing.T) {
+	t.Run("Plain Keys", func(t *testing.T) {
+		trace, err := ParseTrace(strings.NewReader("a\nb\n\n# comment\na\n"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(trace) != 3 {
+			t.Fatalf("Expected 3 accesses, got %d", len(trace))
+		}
+		for _, access := range trace {
+			if access.Op != OpGet || access.Size != 1 {
+				t.Errorf("Expected get of size 1, got %+v", access)
+			}
+		}
+	})
+
+	t.Run("CSV With Header", func(t *testing.T) {
+		input := "op,key,size\nget,a,10\nput,b,20\ndelete,a\n"
+		trace, err := ParseTrace(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []Access{
+			{Op: OpGet, Key: "a", Size: 10},
+			{Op: OpPut, Key: "b", Size: 20},
+			{Op: OpDelete, Key: "a", Size: 1},
+		}
+		if len(trace) != len(expected) {
+			t.Fatalf("Expected %d accesses, got %d", len(expected), len(trace))
+		}
+		for i := range expected {
+			if trace[i] != expected[i] {
+				t.Errorf("Access %d: expected %+v, got %+v", i, expected[i], trace[i])
+			}
+		}
+	})
+
+	t.Run("Keys With Quotes And Commas", func(t *testing.T) {
+		input := "/say \"hi\"\nGET /a?x=1,2\nevict,a\nget,\"b,c\",5\n"
+		trace, err := ParseTrace(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []Access{
+			{Op: OpGet, Key: `/say "hi"`, Size: 1},
+			{Op: OpGet, Key: "GET /a?x=1,2", Size: 1},
+			{Op: OpGet, Key: "evict,a", Size: 1},
+			{Op: OpGet, Key: "b,c", Size: 5},
+		}
+		if len(trace) != len(expected) {
+			t.Fatalf("Expected %d accesses, got %d", len(expected), len(trace))
+		}
+		for i := range expected {
+			if trace[i] != expected[i] {
+				t.Errorf("Access %d: expected %+v, got %+v", i, expected[i], trace[i])
+			}
+		}
+	})
+
+	t.Run("Invalid Records", func(t *testing.T) {
+		for _, input := range []string{"get,a,-1\n", "get,a,1,extra\n", "put,\"a\n"} {
+			if _, err := ParseTrace(strings.NewReader(input)); err == nil {
+				t.Errorf("Expected error for %q", input)
+			}
+		}
+	})
+}
+
+func TestSimulate(t *testing.T) {
+	// a b a c b: with capacity 2, LRU evicts b after a is re-read while FIFO
+	// evicts a, so only FIFO hits on the final b.
+	trace := []Access{
+		{Op: OpGet, Key: "a", Size: 1},
+		{Op: OpGet, Key: "b", Size: 1},
+		{Op: OpGet, Key: "a", Size: 1},
+		{Op: OpGet, Key: "c", Size: 1},
+		{Op: OpGet, Key: "b", Size: 1},
+	}
+
+	lru := Simulate(cache.LRU, 2, trace)
+	if lru.Requests != 5 || lru.Hits != 1 {
+		t.Errorf("Expected LRU 1/5 hits, got %d/%d", lru.Hits, lru.Requests)
+	}
+
+	fifo := Simulate(cache.FIFO, 2, trace)
+	if fifo.Requests != 5 || fifo.Hits != 2 {
+		t.Errorf("Expected FIFO 2/5 hits, got %d/%d", fifo.Hits, fifo.Requests)
+	}
+
+	large := Simulate(cache.LFU, 3, trace)
+	if large.Hits != 2 {
+		t.Errorf("Expected 2 hits once every key fits, got %d", large.Hits)
+	}
+	if large.HitRate != 0.4 {
+		t.Errorf("Expected hit rate 0.4, got %f", large.HitRate)
+	}
+}
+
+func TestDefaultCapacities(t *testing.T) {
+	var trace []Access
+	for _, key := range strings.Split("abcdefghij", "") {
+		trace = append(trace, Access{Op: OpGet, Key: key, Size: 1})
+	}
+
+	capacities := DefaultCapacities(trace)
+	expected := []int{1, 2, 4, 8, 10}
+	if len(capacities) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, capacities)
+	}
+	for i := range expected {
+		if capacities[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, capacities)
+			break
+		}
+	}
+}
+
+func TestRunFormats(t *testing.T) {
+	input := "a\nb\na\nc\na\n"
+
+	t.Run("Table", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := run([]string{"-capacities", "1,2"}, strings.NewReader(input), &out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("Expected header and 2 rows, got:\n%s", out.String())
+		}
+		for _, p := range cache.Policies {
+			if !strings.Contains(lines[0], p.String()) {
+				t.Errorf("Expected header to contain %s, got %q", p, lines[0])
+			}
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		var out bytes.Buffer
+		args := []string{"-capacities", "2", "-policies", "lru,fifo", "-format", "csv"}
+		if err := run(args, strings.NewReader(input), &out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("Expected header and 2 records, got:\n%s", out.String())
+		}
+		if !strings.HasPrefix(lines[1], "LRU,2,5,") {
+			t.Errorf("Unexpected LRU record %q", lines[1])
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		args := []string{"-capacities", "3", "-format", "json"}
+		if err := run(args, strings.NewReader(input), &out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var results []Result
+		if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+			t.Fatalf("Invalid JSON output: %v", err)
+		}
+		if len(results) != len(cache.Policies) {
+			t.Fatalf("Expected %d results, got %d", len(cache.Policies), len(results))
+		}
+		for _, r := range results {
+			if r.Hits != 2 {
+				t.Errorf("%s: expected 2 hits with every key cached, got %d", r.Policy, r.Hits)
+			}
+		}
+	})
+
+	t.Run("Bad Flags", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"-format", "xml"},
+			{"-policies", "mru"},
+			{"-capacities", "0"},
+		} {
+			if err := run(args, strings.NewReader(input), &bytes.Buffer{}); err == nil {
+				t.Errorf("Expected error for %v", args)
+			}
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+//
+// Read-Optimized Concurrent Cache
+//
+// ConcurrentCache separates the data from the eviction policy. Values live in
+// a sync.Map so Get never takes a lock; instead of updating the policy inline,
+// each read is recorded in one of several striped ring buffers. The buffers
+// are drained into the policy in batches by whichever goroutine next holds the
+// policy lock: every writer drains before mutating, and a reader that finds its
+// buffer full drains only if it can take the lock without waiting.
+//
+// Because events from different stripes are replayed stripe by stripe, and
+// reads are dropped when a buffer is full and the lock is busy, the policy
+// sees an approximation of the true access order. Hit/miss accounting is
+// exact.
+//
+
+const readBufferSize = 16 // must be a power of two
+
+type concurrentEntry struct {
+	key   string
+	value interface{}
+}
+
+// readBuffer is a lossy multi-producer, single-consumer ring of read events.
+// Producers claim a slot with a CAS on tail; the consumer (holding the policy
+// lock) advances head.
+type readBuffer struct {
+	head   atomic.Uint32
+	tail   atomic.Uint32
+	slots  [readBufferSize]atomic.Pointer[concurrentEntry]
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	_      [64]byte // keep neighbouring stripes on separate cache lines
+}
+
+// offer records e and reports whether there was room for it.
+func (b *readBuffer) offer(e *concurrentEntry) bool {
+	for {
+		tail := b.tail.Load()
+		if tail-b.head.Load() >= readBufferSize {
+			return false
+		}
+		if b.tail.CompareAndSwap(tail, tail+1) {
+			b.slots[tail&(readBufferSize-1)].Store(e)
+			return true
+		}
+	}
+}
+
+// drain hands every published event to apply. A slot that has been claimed
+// but not yet written stops the drain; it is picked up next time.
+func (b *readBuffer) drain(apply func(*concurrentEntry)) {
+	head, tail := b.head.Load(), b.tail.Load()
+	for ; head != tail; head++ {
+		e := b.slots[head&(readBufferSize-1)].Swap(nil)
+		if e == nil {
+			break
+		}
+		apply(e)
+	}
+	b.head.Store(head)
+}
+
+type ConcurrentCache struct {
+	data    sync.Map // string -> *concurrentEntry
+	mu      sync.Mutex
+	policy  Cache
+	buffers []readBuffer
+	mask    uint32
+}
+
+// NewConcurrentCache creates a read-optimized thread-safe cache whose
+// eviction decisions are made by a cache of the given policy.
+func NewConcurrentCache(policy CachePolicy, capacity int) *ConcurrentCache {
+	if capacity <= 0 {
+		return nil
+	}
+
+	stripes := 1
+	for stripes < 4*runtime.GOMAXPROCS(0) {
+		stripes <<= 1
+	}
+
+	c := &ConcurrentCache{
+		policy:  NewCache(policy, capacity),
+		buffers: make([]readBuffer, stripes),
+		mask:    uint32(stripes - 1),
+	}
+	c.policy.(policyCache).setEvictHook(func(key string, _ interface{}) {
+		c.data.Delete(key)
+	})
+	return c
+}
+
+func (c *ConcurrentCache) stripe() *readBuffer {
+	return &c.buffers[rand.Uint32()&c.mask]
+}
+
+func (c *ConcurrentCache) Get(key string) (interface{}, bool) {
+	buf := c.stripe()
+	v, ok := c.data.Load(key)
+	if !ok {
+		buf.misses.Add(1)
+		return nil, false
+	}
+	buf.hits.Add(1)
+
+	e := v.(*concurrentEntry)
+	if !buf.offer(e) && c.mu.TryLock() {
+		c.drainLocked()
+		c.mu.Unlock()
+		buf.offer(e)
+	}
+	return e.value, true
+}
+
+// drainLocked replays buffered reads into the policy.
+// Must be called with c.mu held.
+func (c *ConcurrentCache) drainLocked() {
+	for i := range c.buffers {
+		c.buffers[i].drain(c.applyRead)
+	}
+}
+
+// applyRead touches key in the policy. Reads of keys that were deleted or
+// evicted after being buffered miss and are ignored.
+func (c *ConcurrentCache) applyRead(e *concurrentEntry) {
+	c.policy.Get(e.key)
+}
+
+func (c *ConcurrentCache) Put(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drainLocked()
+
+	// Entries are immutable so lock-free readers never see a torn update.
+	c.data.Store(key, &concurrentEntry{key: key, value: value})
+	c.policy.Put(key, nil)
+}
+
+func (c *ConcurrentCache) Delete(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drainLocked()
+
+	c.policy.Delete(key)
+	_, existed := c.data.LoadAndDelete(key)
+	return existed
+}
+
+func (c *ConcurrentCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drainLocked()
+
+	c.policy.Clear()
+	c.data.Clear()
+	for i := range c.buffers {
+		c.buffers[i].hits.Store(0)
+		c.buffers[i].misses.Store(0)
+	}
+}
+
+func (c *ConcurrentCache) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy.Size()
+}
+
+func (c *ConcurrentCache) Capacity() int { return c.policy.Capacity() }
+
+func (c *ConcurrentCache) HitRate() float64 {
+	var hits, misses uint64
+	for i := range c.buffers {
+		hits += c.buffers[i].hits.Load()
+		misses += c.buffers[i].misses.Load()
+	}
+	total := hits + misses
+	if total == 0 {
+		return 0.0
+	}
+	return float64(hits) / float64(total)
+}
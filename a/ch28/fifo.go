@@ -8,10 +8,11 @@ import "go-interview/a/ch28/list"
 
 type FIFOCache struct {
 	capacity int
-	cache    map[string]*list.SinglyNode[cachePayload]
-	list     *list.SinglyLinkedList[cachePayload]
+	cache    map[string]*list.DoublyNode[cachePayload]
+	list     *list.DoublyLinkedList[cachePayload]
 	hits     uint64
 	misses   uint64
+	onEvict  func(key string, value interface{})
 }
 
 func NewFIFOCache(capacity int) *FIFOCache {
@@ -20,8 +21,8 @@ func NewFIFOCache(capacity int) *FIFOCache {
 	}
 	return &FIFOCache{
 		capacity: capacity,
-		cache:    make(map[string]*list.SinglyNode[cachePayload]),
-		list:     list.NewSingly[cachePayload](),
+		cache:    make(map[string]*list.DoublyNode[cachePayload]),
+		list:     list.NewDoublyWithSlab(list.NewSlab[cachePayload](0)),
 	}
 }
 
@@ -42,42 +43,57 @@ func (c *FIFOCache) Put(key string, value interface{}) {
 		node.Value.value = value
 		return
 	}
-	if c.list.Len >= c.capacity {
-		front := c.list.Front()
-		if front != nil {
-			delete(c.cache, front.Value.key)
-			c.list.RemoveFront()
-		}
+	if c.list.Len() >= c.capacity {
+		c.evictOne()
 	}
 	node := c.list.PushBack(cachePayload{key: key, value: value})
 	c.cache[key] = node
 }
 
+// evictOne removes the oldest entry, which sits at the front of the queue.
+func (c *FIFOCache) evictOne() bool {
+	oldest := c.list.Front()
+	if oldest == nil {
+		return false
+	}
+	payload := oldest.Value
+	delete(c.cache, payload.key)
+	c.list.Free(oldest)
+	c.evicted(payload.key, payload.value)
+	return true
+}
+
+func (c *FIFOCache) contains(key string) bool {
+	_, ok := c.cache[key]
+	return ok
+}
+
+func (c *FIFOCache) setEvictHook(fn func(key string, value interface{})) { c.onEvict = fn }
+
+func (c *FIFOCache) evicted(key string, value interface{}) {
+	if c.onEvict != nil {
+		c.onEvict(key, value)
+	}
+}
+
 func (c *FIFOCache) Delete(key string) bool {
-	// Deleting from a singly linked list by key is O(N).
-	// This implementation is simplified and doesn't support efficient deletion.
-	// For a production-ready FIFO with O(1) delete, a doubly linked list would be better.
-	if _, ok := c.cache[key]; !ok {
+	node, ok := c.cache[key]
+	if !ok {
 		return false
 	}
-	
-	// To properly delete, we would need to rebuild the list or traverse it.
-	// For this exercise, we'll just remove from the map, acknowledging the list inconsistency.
 	delete(c.cache, key)
-	// A more robust implementation would re-create the list or use a doubly-linked list.
-	// c.list = ... rebuild ...
-	// Since Size() is based on the map, it will be correct.
+	c.list.Free(node)
 	return true
 }
 
 func (c *FIFOCache) Clear() {
-	c.cache = make(map[string]*list.SinglyNode[cachePayload])
-	c.list = list.NewSingly[cachePayload]()
+	c.cache = make(map[string]*list.DoublyNode[cachePayload])
+	c.list = list.NewDoublyWithSlab(list.NewSlab[cachePayload](0))
 	c.hits = 0
 	c.misses = 0
 }
 
-func (c *FIFOCache) Size() int { return len(c.cache) }
+func (c *FIFOCache) Size() int { return c.list.Len() }
 
 func (c *FIFOCache) Capacity() int { return c.capacity }
 
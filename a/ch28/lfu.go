@@ -17,8 +17,10 @@ type LFUCache struct {
 	minFreq    int
 	cache      map[string]*list.DoublyNode[lfuPayload]
 	freqGroups map[int]*list.DoublyLinkedList[lfuPayload]
+	slab       *list.Slab[lfuPayload] // shared by every frequency list
 	hits       uint64
 	misses     uint64
+	onEvict    func(key string, value interface{})
 }
 
 func NewLFUCache(capacity int) *LFUCache {
@@ -29,6 +31,7 @@ func NewLFUCache(capacity int) *LFUCache {
 		capacity:   capacity,
 		cache:      make(map[string]*list.DoublyNode[lfuPayload]),
 		freqGroups: make(map[int]*list.DoublyLinkedList[lfuPayload]),
+		slab:       list.NewSlab[lfuPayload](0),
 	}
 }
 
@@ -53,20 +56,13 @@ func (c *LFUCache) Put(key string, value interface{}) {
 		return
 	}
 	if len(c.cache) >= c.capacity {
-		oldestFreqList := c.freqGroups[c.minFreq] // Renamed local variable
-		if oldestFreqList != nil {
-			nodeToEvict := oldestFreqList.Back()
-			if nodeToEvict != nil {
-				oldestFreqList.Remove(nodeToEvict)
-				delete(c.cache, nodeToEvict.Value.key)
-			}
-		}
+		c.evictOne()
 	}
 	c.minFreq = 1
 	payload := lfuPayload{key: key, value: value, freq: 1}
 	newList, exists := c.freqGroups[1]
 	if !exists {
-		newList = list.NewDoubly[lfuPayload]()
+		newList = list.NewDoublyWithSlab(c.slab)
 		c.freqGroups[1] = newList
 	}
 	node := newList.PushFront(payload)
@@ -78,7 +74,7 @@ func (c *LFUCache) updateNodeFreq(node *list.DoublyNode[lfuPayload]) {
 	oldFreqList := c.freqGroups[oldFreq] // Renamed local variable
 	oldFreqList.Remove(node)
 
-	if oldFreq == c.minFreq && oldFreqList.Len == 0 {
+	if oldFreq == c.minFreq && oldFreqList.Len() == 0 {
 		delete(c.freqGroups, oldFreq)
 		c.minFreq++
 	}
@@ -88,22 +84,65 @@ func (c *LFUCache) updateNodeFreq(node *list.DoublyNode[lfuPayload]) {
 
 	newList, exists := c.freqGroups[newFreq]
 	if !exists {
-		newList = list.NewDoubly[lfuPayload]()
+		newList = list.NewDoublyWithSlab(c.slab)
 		c.freqGroups[newFreq] = newList
 	}
 	newList.PushFrontNode(node)
 }
 
+// evictOne removes the least recently used entry among those with the
+// lowest frequency. minFreq can be stale after Delete, in which case the
+// lowest populated frequency is looked up.
+func (c *LFUCache) evictOne() bool {
+	if len(c.cache) == 0 {
+		return false
+	}
+	oldestFreqList := c.freqGroups[c.minFreq]
+	if oldestFreqList == nil || oldestFreqList.Len() == 0 {
+		c.minFreq = 0
+		for freq, freqList := range c.freqGroups {
+			if freqList.Len() > 0 && (c.minFreq == 0 || freq < c.minFreq) {
+				c.minFreq = freq
+			}
+		}
+		oldestFreqList = c.freqGroups[c.minFreq]
+	}
+
+	nodeToEvict := oldestFreqList.Back()
+	payload := nodeToEvict.Value
+	oldestFreqList.Free(nodeToEvict)
+	if oldestFreqList.Len() == 0 {
+		delete(c.freqGroups, c.minFreq)
+	}
+	delete(c.cache, payload.key)
+	c.evicted(payload.key, payload.value)
+	return true
+}
+
+func (c *LFUCache) contains(key string) bool {
+	_, ok := c.cache[key]
+	return ok
+}
+
+func (c *LFUCache) setEvictHook(fn func(key string, value interface{})) { c.onEvict = fn }
+
+func (c *LFUCache) evicted(key string, value interface{}) {
+	if c.onEvict != nil {
+		c.onEvict(key, value)
+	}
+}
+
 func (c *LFUCache) Delete(key string) bool {
 	node, ok := c.cache[key]
 	if !ok {
 		return false
 	}
 	delete(c.cache, key)
-	freqList := c.freqGroups[node.Value.freq] // Renamed local variable
-	freqList.Remove(node)
-	if freqList.Len == 0 {
-		delete(c.freqGroups, node.Value.freq)
+	freq := node.Value.freq
+	freqList := c.freqGroups[freq] // Renamed local variable
+	freqList.Free(node)
+	if freqList.Len() == 0 {
+		delete(c.freqGroups, freq)
 	}
 	return true
 }
@@ -0,0 +1,135 @@
+package list
+
+import "iter"
+
+// --- Deque ---
+
+const minDequeCap = 8
+
+// Deque is a generic double-ended queue backed by a growable ring buffer.
+// The zero value is an empty deque ready to use.
+type Deque[T any] struct {
+	buf  []T
+	head int // index of the front element
+	size int
+}
+
+// NewDeque creates a new, empty deque.
+func NewDeque[T any]() *Deque[T] {
+	return &Deque[T]{}
+}
+
+// Len returns the number of elements in the deque.
+func (d *Deque[T]) Len() int { return d.size }
+
+// PushBack adds a value to the back of the deque.
+func (d *Deque[T]) PushBack(value T) {
+	d.grow()
+	d.buf[d.index(d.size)] = value
+	d.size++
+}
+
+// PushFront adds a value to the front of the deque.
+func (d *Deque[T]) PushFront(value T) {
+	d.grow()
+	d.head = d.index(len(d.buf) - 1)
+	d.buf[d.head] = value
+	d.size++
+}
+
+// PopFront removes and returns the front value. ok is false if the deque is empty.
+func (d *Deque[T]) PopFront() (value T, ok bool) {
+	if d.size == 0 {
+		return value, false
+	}
+	var zero T
+	value, d.buf[d.head] = d.buf[d.head], zero
+	d.head = d.index(1)
+	d.size--
+	return value, true
+}
+
+// PopBack removes and returns the back value. ok is false if the deque is empty.
+func (d *Deque[T]) PopBack() (value T, ok bool) {
+	if d.size == 0 {
+		return value, false
+	}
+	var zero T
+	i := d.index(d.size - 1)
+	value, d.buf[i] = d.buf[i], zero
+	d.size--
+	return value, true
+}
+
+// Front returns the front value without removing it.
+func (d *Deque[T]) Front() (value T, ok bool) {
+	if d.size == 0 {
+		return value, false
+	}
+	return d.buf[d.head], true
+}
+
+// Back returns the back value without removing it.
+func (d *Deque[T]) Back() (value T, ok bool) {
+	if d.size == 0 {
+		return value, false
+	}
+	return d.buf[d.index(d.size-1)], true
+}
+
+// At returns the i-th value from the front. It panics if i is out of range.
+func (d *Deque[T]) At(i int) T {
+	if i < 0 || i >= d.size {
+		panic("list: Deque index out of range")
+	}
+	return d.buf[d.index(i)]
+}
+
+// Clear removes all values, keeping the allocated buffer.
+func (d *Deque[T]) Clear() {
+	clear(d.buf)
+	d.head = 0
+	d.size = 0
+}
+
+// All returns an iterator over the values from front to back.
+func (d *Deque[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < d.size; i++ {
+			if !yield(d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the values from back to front.
+func (d *Deque[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := d.size - 1; i >= 0; i-- {
+			if !yield(d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// index maps an offset from the front to a buffer position.
+func (d *Deque[T]) index(offset int) int {
+	return (d.head + offset) % len(d.buf)
+}
+
+// grow doubles the buffer when it is full, unwrapping the contents so the
+// front lands at position 0.
+func (d *Deque[T]) grow() {
+	if d.size < len(d.buf) {
+		return
+	}
+	buf := make([]T, max(minDequeCap, 2*len(d.buf)))
+	if d.size > 0 {
+		n := copy(buf, d.buf[d.head:])
+		copy(buf[n:], d.buf[:d.head])
+	}
+	d.buf = buf
+	d.head = 0
+}
@@ -0,0 +1,139 @@
+package list
+
+// --- Indexed Heap ---
+
+// HeapNode is a handle to a value stored in a Heap. It stays valid while the
+// value is in the heap and can be passed to Fix or Remove.
+type HeapNode[T any] struct {
+	Value T
+	index int
+	heap  *Heap[T] // owning heap, nil once removed
+}
+
+// Heap is a generic binary min-heap ordered by a less function, with handles
+// that allow updating or removing arbitrary values in O(log n). With a less
+// function comparing deadlines it serves as an expiry queue.
+type Heap[T any] struct {
+	nodes []*HeapNode[T]
+	less  func(a, b T) bool
+}
+
+// NewHeap creates an empty heap where Peek and Pop return the value for which
+// less reports true against every other value.
+func NewHeap[T any](less func(a, b T) bool) *Heap[T] {
+	return &Heap[T]{less: less}
+}
+
+// Len returns the number of values in the heap.
+func (h *Heap[T]) Len() int { return len(h.nodes) }
+
+// Push adds a value and returns its handle.
+func (h *Heap[T]) Push(value T) *HeapNode[T] {
+	node := &HeapNode[T]{Value: value, index: len(h.nodes), heap: h}
+	h.nodes = append(h.nodes, node)
+	h.up(node.index)
+	return node
+}
+
+// Peek returns the handle of the smallest value, or nil if the heap is empty.
+func (h *Heap[T]) Peek() *HeapNode[T] {
+	if len(h.nodes) == 0 {
+		return nil
+	}
+	return h.nodes[0]
+}
+
+// Pop removes and returns the smallest value. ok is false if the heap is empty.
+func (h *Heap[T]) Pop() (value T, ok bool) {
+	if len(h.nodes) == 0 {
+		return value, false
+	}
+	return h.removeAt(0).Value, true
+}
+
+// Fix restores heap order after node.Value has been changed in place.
+// It is a no-op if the node does not belong to h.
+func (h *Heap[T]) Fix(node *HeapNode[T]) {
+	if node.heap != h {
+		return
+	}
+	if !h.down(node.index) {
+		h.up(node.index)
+	}
+}
+
+// Remove removes node from the heap and reports whether it was removed.
+// Nodes that belong to another heap, or were already removed, are left
+// untouched.
+func (h *Heap[T]) Remove(node *HeapNode[T]) bool {
+	if node.heap != h {
+		return false
+	}
+	h.removeAt(node.index)
+	return true
+}
+
+// Clear removes all values, detaching their handles.
+func (h *Heap[T]) Clear() {
+	for _, node := range h.nodes {
+		node.heap = nil
+		node.index = -1
+	}
+	clear(h.nodes)
+	h.nodes = h.nodes[:0]
+}
+
+func (h *Heap[T]) removeAt(i int) *HeapNode[T] {
+	last := len(h.nodes) - 1
+	node := h.nodes[i]
+	if i != last {
+		h.swap(i, last)
+	}
+	h.nodes[last] = nil
+	h.nodes = h.nodes[:last]
+	if i != last {
+		if !h.down(i) {
+			h.up(i)
+		}
+	}
+	node.heap = nil
+	node.index = -1
+	return node
+}
+
+func (h *Heap[T]) swap(i, j int) {
+	h.nodes[i], h.nodes[j] = h.nodes[j], h.nodes[i]
+	h.nodes[i].index = i
+	h.nodes[j].index = j
+}
+
+func (h *Heap[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.nodes[i].Value, h.nodes[parent].Value) {
+			return
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+// down sifts the value at i towards the leaves and reports whether it moved.
+func (h *Heap[T]) down(i int) bool {
+	start, n := i, len(h.nodes)
+	for {
+		smallest := 2*i + 1
+		if smallest >= n {
+			break
+		}
+		if right := smallest + 1; right < n && h.less(h.nodes[right].Value, h.nodes[smallest].Value) {
+			smallest = right
+		}
+		if !h.less(h.nodes[smallest].Value, h.nodes[i].Value) {
+			break
+		}
+		h.swap(i, smallest)
+		i = smallest
+	}
+	return i > start
+}
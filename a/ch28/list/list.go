@@ -1,6 +1,9 @@
-// Package list provides generic implementations of singly and doubly linked lists.
+// Package list provides generic containers: singly and doubly linked lists,
+// a deque, a fixed-size ring buffer and an indexed min-heap.
 package list
 
+import "iter"
+
 // --- Doubly Linked List ---
 
 // DoublyNode is a node in a doubly linked list.
@@ -8,6 +11,7 @@ type DoublyNode[T any] struct {
 	Value T
 	prev  *DoublyNode[T]
 	next  *DoublyNode[T]
+	list  *DoublyLinkedList[T] // owning list, nil once removed
 }
 
 // Prev returns the previous list node or nil.
@@ -20,7 +24,8 @@ func (n *DoublyNode[T]) Next() *DoublyNode[T] { return n.next }
 type DoublyLinkedList[T any] struct {
 	head *DoublyNode[T]
 	tail *DoublyNode[T]
-	Len  int
+	size int
+	slab *Slab[T] // optional node allocator
 }
 
 // NewDoubly creates a new, empty doubly linked list.
@@ -28,6 +33,15 @@ func NewDoubly[T any]() *DoublyLinkedList[T] {
 	return &DoublyLinkedList[T]{}
 }
 
+// NewDoublyWithSlab creates a new, empty doubly linked list whose nodes are
+// allocated from slab. Several lists may share one slab.
+func NewDoublyWithSlab[T any](slab *Slab[T]) *DoublyLinkedList[T] {
+	return &DoublyLinkedList[T]{slab: slab}
+}
+
+// Len returns the number of nodes in the list.
+func (l *DoublyLinkedList[T]) Len() int { return l.size }
+
 // Front returns the first node of the list or nil if the list is empty.
 func (l *DoublyLinkedList[T]) Front() *DoublyNode[T] {
 	return l.head
@@ -40,30 +54,210 @@ func (l *DoublyLinkedList[T]) Back() *DoublyNode[T] {
 
 // PushFront adds a new node with the given value to the front of the list.
 func (l *DoublyLinkedList[T]) PushFront(value T) *DoublyNode[T] {
-	node := &DoublyNode[T]{Value: value}
+	node := l.newNode(value)
 	l.PushFrontNode(node) // Use the helper to add the new node
 	return node
 }
 
-// PushFrontNode adds an existing node to the front of the list.
-// The node must be detached from any other list before calling this.
-func (l *DoublyLinkedList[T]) PushFrontNode(node *DoublyNode[T]) {
-	node.prev = nil // Ensure the node is detached from its previous context
-	node.next = nil // Ensure the node is detached from its previous context
+// PushFrontNode adds a detached node to the front of the list and reports
+// whether it was added. A node still owned by any list is left untouched.
+func (l *DoublyLinkedList[T]) PushFrontNode(node *DoublyNode[T]) bool {
+	if node.list != nil {
+		return false
+	}
+	l.link(node, nil, l.head)
+	return true
+}
 
-	if l.head == nil {
-		l.head = node
-		l.tail = node
+// PushBack adds a new node with the given value to the back of the list.
+func (l *DoublyLinkedList[T]) PushBack(value T) *DoublyNode[T] {
+	node := l.newNode(value)
+	l.link(node, l.tail, nil)
+	return node
+}
+
+// InsertBefore inserts a new node with the given value immediately before
+// mark and returns it. It returns nil if mark is not an element of l.
+func (l *DoublyLinkedList[T]) InsertBefore(value T, mark *DoublyNode[T]) *DoublyNode[T] {
+	if mark.list != l {
+		return nil
+	}
+	node := l.newNode(value)
+	l.link(node, mark.prev, mark)
+	return node
+}
+
+// InsertAfter inserts a new node with the given value immediately after
+// mark and returns it. It returns nil if mark is not an element of l.
+func (l *DoublyLinkedList[T]) InsertAfter(value T, mark *DoublyNode[T]) *DoublyNode[T] {
+	if mark.list != l {
+		return nil
+	}
+	node := l.newNode(value)
+	l.link(node, mark, mark.next)
+	return node
+}
+
+// Remove removes a node from the list and reports whether it was removed.
+// Nodes that belong to another list, or were already removed, are left
+// untouched.
+func (l *DoublyLinkedList[T]) Remove(node *DoublyNode[T]) bool {
+	if node.list != l {
+		return false
+	}
+	l.unlink(node)
+	return true
+}
+
+// Free removes a node from the list and, if the list has a slab, returns the
+// node to it for reuse. The node must not be used after Free returns true.
+func (l *DoublyLinkedList[T]) Free(node *DoublyNode[T]) bool {
+	if !l.Remove(node) {
+		return false
+	}
+	if l.slab != nil {
+		l.slab.release(node)
+	}
+	return true
+}
+
+// MoveToFront moves a node to the front of the list.
+// It is a no-op if the node is not an element of l.
+func (l *DoublyLinkedList[T]) MoveToFront(node *DoublyNode[T]) {
+	if node.list != l || l.head == node {
+		return
+	}
+	l.unlink(node)
+	l.link(node, nil, l.head)
+}
+
+// MoveToBack moves a node to the back of the list.
+// It is a no-op if the node is not an element of l.
+func (l *DoublyLinkedList[T]) MoveToBack(node *DoublyNode[T]) {
+	if node.list != l || l.tail == node {
+		return
+	}
+	l.unlink(node)
+	l.link(node, l.tail, nil)
+}
+
+// MoveBefore moves node to immediately before mark.
+// It is a no-op if either node is not an element of l, or node == mark.
+func (l *DoublyLinkedList[T]) MoveBefore(node, mark *DoublyNode[T]) {
+	if node.list != l || mark.list != l || node == mark {
+		return
+	}
+	l.unlink(node)
+	l.link(node, mark.prev, mark)
+}
+
+// MoveAfter moves node to immediately after mark.
+// It is a no-op if either node is not an element of l, or node == mark.
+func (l *DoublyLinkedList[T]) MoveAfter(node, mark *DoublyNode[T]) {
+	if node.list != l || mark.list != l || node == mark {
+		return
+	}
+	l.unlink(node)
+	l.link(node, mark, mark.next)
+}
+
+// PushBackList appends a copy of every value in other to the back of the list.
+// other may be l itself.
+func (l *DoublyLinkedList[T]) PushBackList(other *DoublyLinkedList[T]) {
+	for i, node := other.size, other.head; i > 0; i, node = i-1, node.next {
+		l.PushBack(node.Value)
+	}
+}
+
+// Splice moves every node of other into the list immediately before mark,
+// leaving other empty. A nil mark splices at the back. It reports false
+// without changing either list if mark is not an element of l or other is l.
+// Ownership of each moved node is updated, so Splice is O(len(other)).
+func (l *DoublyLinkedList[T]) Splice(mark *DoublyNode[T], other *DoublyLinkedList[T]) bool {
+	if other == l || (mark != nil && mark.list != l) {
+		return false
+	}
+	if other.head == nil {
+		return true
+	}
+
+	for node := other.head; node != nil; node = node.next {
+		node.list = l
+	}
+	first, last := other.head, other.tail
+
+	prev := l.tail
+	if mark != nil {
+		prev = mark.prev
+	}
+	first.prev = prev
+	last.next = mark
+	if prev != nil {
+		prev.next = first
+	} else {
+		l.head = first
+	}
+	if mark != nil {
+		mark.prev = last
+	} else {
+		l.tail = last
+	}
+
+	l.size += other.size
+	other.head, other.tail, other.size = nil, nil, 0
+	return true
+}
+
+// All returns an iterator over the list values from front to back.
+func (l *DoublyLinkedList[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for node := l.head; node != nil; node = node.next {
+			if !yield(node.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the list values from back to front.
+func (l *DoublyLinkedList[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for node := l.tail; node != nil; node = node.prev {
+			if !yield(node.Value) {
+				return
+			}
+		}
+	}
+}
+
+func (l *DoublyLinkedList[T]) newNode(value T) *DoublyNode[T] {
+	if l.slab != nil {
+		return l.slab.alloc(value)
+	}
+	return &DoublyNode[T]{Value: value}
+}
+
+// link inserts node between prev and next, either of which may be nil at
+// the ends of the list, and records l as its owner.
+func (l *DoublyLinkedList[T]) link(node, prev, next *DoublyNode[T]) {
+	node.prev = prev
+	node.next = next
+	node.list = l
+	if prev != nil {
+		prev.next = node
 	} else {
-		node.next = l.head
-		l.head.prev = node
 		l.head = node
 	}
-	l.Len++
+	if next != nil {
+		next.prev = node
+	} else {
+		l.tail = node
+	}
+	l.size++
 }
 
-// Remove removes a node from the list.
-func (l *DoublyLinkedList[T]) Remove(node *DoublyNode[T]) {
+// unlink detaches a node owned by l.
+func (l *DoublyLinkedList[T]) unlink(node *DoublyNode[T]) {
 	if node.prev != nil {
 		node.prev.next = node.next
 	} else {
@@ -76,16 +270,60 @@ func (l *DoublyLinkedList[T]) Remove(node *DoublyNode[T]) {
 	}
 	node.prev = nil // Detach node from the list
 	node.next = nil // Detach node from the list
-	l.Len--
+	node.list = nil
+	l.size--
 }
 
-// MoveToFront moves a node to the front of the list.
-func (l *DoublyLinkedList[T]) MoveToFront(node *DoublyNode[T]) {
-	if l.head == node {
-		return // Already at the front
+// --- Slab Allocator ---
+
+const defaultSlabChunk = 64
+
+// Slab allocates DoublyNodes in contiguous chunks and recycles nodes released
+// by DoublyLinkedList.Free, so a list whose length stays bounded stops
+// allocating once warmed up. Chunks never move, so node pointers stay valid.
+// A Slab is not safe for concurrent use.
+type Slab[T any] struct {
+	chunkSize int
+	chunk     []DoublyNode[T]     // unused tail of the newest chunk
+	free      *DoublyNode[T]      // released nodes, linked through next
+	freed     DoublyLinkedList[T] // sentinel owner of released nodes
+}
+
+// NewSlab creates a slab that grows chunkSize nodes at a time
+// (64 if chunkSize is not positive).
+func NewSlab[T any](chunkSize int) *Slab[T] {
+	if chunkSize <= 0 {
+		chunkSize = defaultSlabChunk
 	}
-	l.Remove(node)
-	l.PushFrontNode(node) // Use the existing node
+	return &Slab[T]{chunkSize: chunkSize}
+}
+
+func (s *Slab[T]) alloc(value T) *DoublyNode[T] {
+	var node *DoublyNode[T]
+	if s.free != nil {
+		node = s.free
+		s.free = node.next
+		node.next = nil
+		node.list = nil
+	} else {
+		if len(s.chunk) == 0 {
+			s.chunk = make([]DoublyNode[T], s.chunkSize)
+		}
+		node = &s.chunk[0]
+		s.chunk = s.chunk[1:]
+	}
+	node.Value = value
+	return node
+}
+
+// release zeroes a detached node and adds it to the free list. Released
+// nodes are owned by a sentinel list so the ownership checks reject them.
+func (s *Slab[T]) release(node *DoublyNode[T]) {
+	var zero T
+	node.Value = zero
+	node.list = &s.freed
+	node.next = s.free
+	s.free = node
 }
 
 // --- Singly Linked List ---
@@ -103,7 +341,7 @@ func (n *SinglyNode[T]) Next() *SinglyNode[T] { return n.next }
 type SinglyLinkedList[T any] struct {
 	head *SinglyNode[T]
 	tail *SinglyNode[T]
-	Len  int
+	size int
 }
 
 // NewSingly creates a new, empty singly linked list.
@@ -111,6 +349,9 @@ func NewSingly[T any]() *SinglyLinkedList[T] {
 	return &SinglyLinkedList[T]{}
 }
 
+// Len returns the number of nodes in the list.
+func (l *SinglyLinkedList[T]) Len() int { return l.size }
+
 // Front returns the first node of the list or nil.
 func (l *SinglyLinkedList[T]) Front() *SinglyNode[T] {
 	return l.head
@@ -126,10 +367,21 @@ func (l *SinglyLinkedList[T]) PushBack(value T) *SinglyNode[T] {
 		l.tail.next = node
 		l.tail = node
 	}
-	l.Len++
+	l.size++
 	return node
 }
 
+// All returns an iterator over the list values from front to back.
+func (l *SinglyLinkedList[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for node := l.head; node != nil; node = node.next {
+			if !yield(node.Value) {
+				return
+			}
+		}
+	}
+}
+
 // RemoveFront removes the first node from the list.
 func (l *SinglyLinkedList[T]) RemoveFront() {
 	if l.head == nil {
@@ -139,5 +391,5 @@ func (l *SinglyLinkedList[T]) RemoveFront() {
 	if l.head == nil {
 		l.tail = nil
 	}
-	l.Len--
+	l.size--
 }
@@ -0,0 +1,651 @@
+package list
+
+import (
+	"container/list"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// checkDoubly verifies the links, ownership and length of l against want.
+func checkDoubly(t *testing.T, l *DoublyLinkedList[int], want []int) {
+	t.Helper()
+	if l.Len() != len(want) {
+		t.Fatalf("Expected Len %d, got %d", len(want), l.Len())
+	}
+	if got := slices.Collect(l.All()); !slices.Equal(got, want) {
+		t.Fatalf("Expected forward %v, got %v", want, got)
+	}
+	backward := slices.Clone(want)
+	slices.Reverse(backward)
+	if got := slices.Collect(l.Backward()); !slices.Equal(got, backward) {
+		t.Fatalf("Expected backward %v, got %v", backward, got)
+	}
+	for node := l.Front(); node != nil; node = node.Next() {
+		if node.list != l {
+			t.Fatalf("Node %v is not owned by the list", node.Value)
+		}
+		if next := node.Next(); next != nil && next.Prev() != node {
+			t.Fatalf("Broken prev link after %v", node.Value)
+		}
+	}
+	if len(want) == 0 && (l.Front() != nil || l.Back() != nil) {
+		t.Fatal("Expected nil Front and Back on an empty list")
+	}
+}
+
+func TestDoublyPushAndInsert(t *testing.T) {
+	l := NewDoubly[int]()
+	two := l.PushBack(2)
+	l.PushFront(1)
+	l.PushBack(4)
+	checkDoubly(t, l, []int{1, 2, 4})
+
+	l.InsertAfter(3, two)
+	l.InsertBefore(0, l.Front())
+	l.InsertAfter(5, l.Back())
+	checkDoubly(t, l, []int{0, 1, 2, 3, 4, 5})
+
+	other := NewDoubly[int]()
+	foreign := other.PushBack(9)
+	if l.InsertBefore(7, foreign) != nil || l.InsertAfter(7, foreign) != nil {
+		t.Error("Expected insert relative to a foreign node to return nil")
+	}
+	checkDoubly(t, l, []int{0, 1, 2, 3, 4, 5})
+	checkDoubly(t, other, []int{9})
+}
+
+func TestDoublyRemove(t *testing.T) {
+	l := NewDoubly[int]()
+	a := l.PushBack(1)
+	b := l.PushBack(2)
+	c := l.PushBack(3)
+
+	if !l.Remove(b) {
+		t.Error("Expected Remove of an owned node to succeed")
+	}
+	checkDoubly(t, l, []int{1, 3})
+
+	if l.Remove(b) {
+		t.Error("Expected second Remove of the same node to fail")
+	}
+	other := NewDoubly[int]()
+	foreign := other.PushBack(9)
+	if l.Remove(foreign) {
+		t.Error("Expected Remove of a foreign node to fail")
+	}
+	checkDoubly(t, l, []int{1, 3})
+	checkDoubly(t, other, []int{9})
+
+	l.Remove(a)
+	l.Remove(c)
+	checkDoubly(t, l, nil)
+}
+
+func TestDoublyMove(t *testing.T) {
+	l := NewDoubly[int]()
+	n1 := l.PushBack(1)
+	n2 := l.PushBack(2)
+	n3 := l.PushBack(3)
+	n4 := l.PushBack(4)
+
+	l.MoveToBack(n1)
+	checkDoubly(t, l, []int{2, 3, 4, 1})
+	l.MoveToFront(n1)
+	checkDoubly(t, l, []int{1, 2, 3, 4})
+	l.MoveBefore(n4, n2)
+	checkDoubly(t, l, []int{1, 4, 2, 3})
+	l.MoveAfter(n1, n3)
+	checkDoubly(t, l, []int{4, 2, 3, 1})
+	l.MoveAfter(n3, n2) // already in place
+	checkDoubly(t, l, []int{4, 2, 3, 1})
+	l.MoveBefore(n2, n2)
+	checkDoubly(t, l, []int{4, 2, 3, 1})
+
+	other := NewDoubly[int]()
+	foreign := other.PushBack(9)
+	l.MoveToBack(foreign)
+	l.MoveBefore(foreign, n1)
+	l.MoveAfter(n1, foreign)
+	checkDoubly(t, l, []int{4, 2, 3, 1})
+	checkDoubly(t, other, []int{9})
+}
+
+func TestDoublyOwnership(t *testing.T) {
+	l := NewDoubly[int]()
+	a := l.PushBack(1)
+	l.PushBack(2)
+	other := NewDoubly[int]()
+	foreign := other.PushBack(9)
+
+	l.MoveToFront(foreign)
+	checkDoubly(t, l, []int{1, 2})
+	checkDoubly(t, other, []int{9})
+
+	if l.PushFrontNode(foreign) {
+		t.Error("Expected PushFrontNode of a node owned by another list to fail")
+	}
+	if l.PushFrontNode(a) {
+		t.Error("Expected PushFrontNode of a node already in the list to fail")
+	}
+	checkDoubly(t, l, []int{1, 2})
+
+	// A removed node is detached: moving it is a no-op, re-adding it works.
+	l.Remove(a)
+	l.MoveToFront(a)
+	checkDoubly(t, l, []int{2})
+	if !other.PushFrontNode(a) {
+		t.Error("Expected PushFrontNode of a detached node to succeed")
+	}
+	checkDoubly(t, other, []int{1, 9})
+	if l.Remove(a) {
+		t.Error("Expected Remove from the previous owner to fail")
+	}
+	checkDoubly(t, l, []int{2})
+}
+
+func TestDoublyPushBackList(t *testing.T) {
+	l := NewDoubly[int]()
+	l.PushBack(1)
+	l.PushBack(2)
+
+	other := NewDoubly[int]()
+	other.PushBack(3)
+	l.PushBackList(other)
+	checkDoubly(t, l, []int{1, 2, 3})
+	checkDoubly(t, other, []int{3})
+
+	l.PushBackList(l)
+	checkDoubly(t, l, []int{1, 2, 3, 1, 2, 3})
+}
+
+func TestDoublySplice(t *testing.T) {
+	build := func(values ...int) *DoublyLinkedList[int] {
+		l := NewDoubly[int]()
+		for _, v := range values {
+			l.PushBack(v)
+		}
+		return l
+	}
+
+	t.Run("Middle", func(t *testing.T) {
+		l := build(1, 4)
+		other := build(2, 3)
+		moved := other.Front()
+		if !l.Splice(l.Back(), other) {
+			t.Fatal("Expected Splice to succeed")
+		}
+		checkDoubly(t, l, []int{1, 2, 3, 4})
+		checkDoubly(t, other, nil)
+		if !l.Remove(moved) {
+			t.Error("Expected spliced node to be owned by the destination")
+		}
+	})
+
+	t.Run("Front And Back", func(t *testing.T) {
+		l := build(2)
+		l.Splice(l.Front(), build(0, 1))
+		l.Splice(nil, build(3))
+		checkDoubly(t, l, []int{0, 1, 2, 3})
+	})
+
+	t.Run("Into Empty", func(t *testing.T) {
+		l := NewDoubly[int]()
+		l.Splice(nil, build(1, 2))
+		checkDoubly(t, l, []int{1, 2})
+		l.Splice(nil, NewDoubly[int]())
+		checkDoubly(t, l, []int{1, 2})
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		l := build(1, 2)
+		if l.Splice(nil, l) {
+			t.Error("Expected Splice of a list into itself to fail")
+		}
+		other := build(3)
+		if l.Splice(other.Front(), build(4)) {
+			t.Error("Expected Splice at a foreign mark to fail")
+		}
+		checkDoubly(t, l, []int{1, 2})
+	})
+}
+
+func TestIteratorsStopEarly(t *testing.T) {
+	l := NewDoubly[int]()
+	for i := 0; i < 5; i++ {
+		l.PushBack(i)
+	}
+
+	var seen []int
+	for v := range l.All() {
+		if v == 2 {
+			break
+		}
+		seen = append(seen, v)
+	}
+	if !slices.Equal(seen, []int{0, 1}) {
+		t.Errorf("Expected [0 1], got %v", seen)
+	}
+
+	s := NewSingly[int]()
+	s.PushBack(1)
+	s.PushBack(2)
+	if got := slices.Collect(s.All()); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("Expected [1 2], got %v", got)
+	}
+}
+
+func TestSlab(t *testing.T) {
+	slab := NewSlab[int](2)
+	a := NewDoublyWithSlab(slab)
+	b := NewDoublyWithSlab(slab)
+
+	n1 := a.PushBack(1)
+	n2 := a.PushBack(2)
+	b.PushBack(3) // starts a second chunk
+	checkDoubly(t, a, []int{1, 2})
+	checkDoubly(t, b, []int{3})
+
+	if b.Free(n1) {
+		t.Error("Expected Free of a node owned by another list to fail")
+	}
+	if !a.Free(n1) || a.Free(n1) {
+		t.Error("Expected Free to succeed exactly once")
+	}
+	checkDoubly(t, a, []int{2})
+
+	// A freed node is neither attached nor detached: nothing may adopt it.
+	if b.PushFrontNode(n1) {
+		t.Error("Expected PushFrontNode of a freed node to fail")
+	}
+	b.MoveToFront(n1)
+	checkDoubly(t, b, []int{3})
+
+	// The freed node is recycled with a fresh value and owner.
+	reused := b.PushFront(4)
+	if reused != n1 {
+		t.Error("Expected the freed node to be reused")
+	}
+	checkDoubly(t, b, []int{4, 3})
+	a.Free(n2)
+	checkDoubly(t, a, nil)
+
+	// Without a slab Free behaves like Remove.
+	plain := NewDoubly[int]()
+	node := plain.PushBack(1)
+	if !plain.Free(node) || !plain.PushFrontNode(node) {
+		t.Error("Expected a freed node of a plain list to be reusable")
+	}
+	checkDoubly(t, plain, []int{1})
+}
+
+// FuzzDoubly replays random operation sequences against container/list and
+// requires identical contents after every step. Both lists track every node
+// ever created, including removed ones, so detached and foreign nodes are
+// exercised as well as live ones.
+func FuzzDoubly(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11})
+	f.Add([]byte{1, 0, 1, 1, 4, 0, 4, 0, 5, 1, 6, 2, 3, 3})
+	f.Add([]byte{2, 9, 2, 9, 8, 1, 10, 0, 11, 7, 7, 5})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		ours, foreignOurs := NewDoubly[int](), NewDoubly[int]()
+		theirs, foreignTheirs := list.New(), list.New()
+		oursNodes := []*DoublyNode[int]{foreignOurs.PushBack(-1)}
+		theirNodes := []*list.Element{foreignTheirs.PushBack(-1)}
+
+		pick := func(b byte) (*DoublyNode[int], *list.Element) {
+			i := int(b) % len(oursNodes)
+			return oursNodes[i], theirNodes[i]
+		}
+
+		for i := 0; i+1 < len(ops); i += 2 {
+			op, arg := ops[i]%12, ops[i+1]
+			value := i
+			n, e := pick(arg)
+			m, d := pick(arg / 7)
+
+			switch op {
+			case 0:
+				oursNodes = append(oursNodes, ours.PushFront(value))
+				theirNodes = append(theirNodes, theirs.PushFront(value))
+			case 1:
+				oursNodes = append(oursNodes, ours.PushBack(value))
+				theirNodes = append(theirNodes, theirs.PushBack(value))
+			case 2:
+				if got := ours.InsertBefore(value, n); got != nil {
+					oursNodes = append(oursNodes, got)
+					theirNodes = append(theirNodes, theirs.InsertBefore(value, e))
+				} else if theirs.InsertBefore(value, e) != nil {
+					t.Fatalf("InsertBefore rejected a node container/list accepted")
+				}
+			case 3:
+				if got := ours.InsertAfter(value, n); got != nil {
+					oursNodes = append(oursNodes, got)
+					theirNodes = append(theirNodes, theirs.InsertAfter(value, e))
+				} else if theirs.InsertAfter(value, e) != nil {
+					t.Fatalf("InsertAfter rejected a node container/list accepted")
+				}
+			case 4, 5:
+				// Remove is weighted double so lists don't only grow.
+				wantOwned := e.Next() != nil || e.Prev() != nil || theirs.Front() == e
+				if got := ours.Remove(n); got != wantOwned {
+					t.Fatalf("Remove reported %v, want %v", got, wantOwned)
+				}
+				theirs.Remove(e)
+			case 6:
+				ours.MoveToFront(n)
+				theirs.MoveToFront(e)
+			case 7:
+				ours.MoveToBack(n)
+				theirs.MoveToBack(e)
+			case 8:
+				ours.MoveBefore(n, m)
+				theirs.MoveBefore(e, d)
+			case 9:
+				ours.MoveAfter(n, m)
+				theirs.MoveAfter(e, d)
+			case 10:
+				ours.PushBackList(ours)
+				theirs.PushBackList(theirs)
+			case 11:
+				ours.PushBackList(foreignOurs)
+				theirs.PushBackList(foreignTheirs)
+			}
+
+			if ours.Len() > 1<<10 {
+				return // PushBackList doubling; nothing new to learn
+			}
+
+			var want []int
+			for x := theirs.Front(); x != nil; x = x.Next() {
+				want = append(want, x.Value.(int))
+			}
+			checkDoubly(t, ours, want)
+			checkDoubly(t, foreignOurs, []int{-1})
+		}
+	})
+}
+
+func BenchmarkDoubly(b *testing.B) {
+	b.Run("PushBackRemove", func(b *testing.B) {
+		l := NewDoubly[int]()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Remove(l.PushBack(i))
+		}
+	})
+
+	// Churn keeps the list at a fixed length, like a full LRU: every push is
+	// paired with a free of the oldest node.
+	for _, alloc := range []string{"Heap", "Slab"} {
+		b.Run("Churn/"+alloc, func(b *testing.B) {
+			l := NewDoubly[int]()
+			if alloc == "Slab" {
+				l = NewDoublyWithSlab(NewSlab[int](0))
+			}
+			for i := 0; i < 1024; i++ {
+				l.PushFront(i)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Free(l.Back())
+				l.PushFront(i)
+			}
+		})
+	}
+
+	b.Run("MoveToFront", func(b *testing.B) {
+		l := NewDoubly[int]()
+		nodes := make([]*DoublyNode[int], 1024)
+		for i := range nodes {
+			nodes[i] = l.PushBack(i)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.MoveToFront(nodes[i%len(nodes)])
+		}
+	})
+
+	b.Run("All", func(b *testing.B) {
+		l := NewDoubly[int]()
+		for i := 0; i < 1024; i++ {
+			l.PushBack(i)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			sum := 0
+			for v := range l.All() {
+				sum += v
+			}
+		}
+	})
+}
+
+// --- Deque, Ring and Heap ---
+
+func TestDeque(t *testing.T) {
+	d := NewDeque[int]()
+	if _, ok := d.PopFront(); ok {
+		t.Error("Expected PopFront on an empty deque to fail")
+	}
+
+	// Mix both ends across several growths.
+	var want []int
+	for i := 0; i < 50; i++ {
+		if i%3 == 0 {
+			d.PushFront(i)
+			want = append([]int{i}, want...)
+		} else {
+			d.PushBack(i)
+			want = append(want, i)
+		}
+	}
+	if got := slices.Collect(d.All()); !slices.Equal(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	backward := slices.Clone(want)
+	slices.Reverse(backward)
+	if got := slices.Collect(d.Backward()); !slices.Equal(got, backward) {
+		t.Fatalf("Expected backward %v, got %v", backward, got)
+	}
+	if d.At(10) != want[10] {
+		t.Errorf("Expected At(10) = %d, got %d", want[10], d.At(10))
+	}
+
+	front, _ := d.PopFront()
+	back, _ := d.PopBack()
+	if front != want[0] || back != want[len(want)-1] || d.Len() != len(want)-2 {
+		t.Errorf("Unexpected pops %d, %d with len %d", front, back, d.Len())
+	}
+	if v, _ := d.Front(); v != want[1] {
+		t.Errorf("Expected front %d, got %d", want[1], v)
+	}
+	if v, _ := d.Back(); v != want[len(want)-2] {
+		t.Errorf("Expected back %d, got %d", want[len(want)-2], v)
+	}
+
+	d.Clear()
+	if d.Len() != 0 {
+		t.Errorf("Expected empty deque after clear, got %d", d.Len())
+	}
+	var zero Deque[string]
+	zero.PushFront("a")
+	if v, ok := zero.PopBack(); !ok || v != "a" {
+		t.Errorf("Expected zero-value deque to work, got (%q, %v)", v, ok)
+	}
+}
+
+func TestRing(t *testing.T) {
+	if NewRing[int](0) != nil {
+		t.Error("Expected nil for zero capacity")
+	}
+
+	r := NewRing[int](3)
+	for i := 1; i <= 3; i++ {
+		if _, overwritten := r.Push(i); overwritten {
+			t.Errorf("Unexpected overwrite pushing %d", i)
+		}
+	}
+	if !r.Full() || r.Cap() != 3 {
+		t.Errorf("Expected full ring of capacity 3")
+	}
+
+	old, overwritten := r.Push(4)
+	if !overwritten || old != 1 {
+		t.Errorf("Expected to overwrite 1, got (%d, %v)", old, overwritten)
+	}
+	if got := slices.Collect(r.All()); !slices.Equal(got, []int{2, 3, 4}) {
+		t.Errorf("Expected [2 3 4], got %v", got)
+	}
+	if r.At(0) != 2 || r.At(2) != 4 {
+		t.Errorf("Unexpected At values %d, %d", r.At(0), r.At(2))
+	}
+
+	if v, ok := r.Pop(); !ok || v != 2 {
+		t.Errorf("Expected to pop 2, got (%d, %v)", v, ok)
+	}
+	r.Push(5)
+	r.Push(6) // overwrites 3
+	if v, _ := r.Peek(); v != 4 || r.Len() != 3 {
+		t.Errorf("Expected oldest 4 with len 3, got %d with len %d", v, r.Len())
+	}
+
+	r.Clear()
+	if _, ok := r.Pop(); ok || r.Len() != 0 {
+		t.Error("Expected empty ring after clear")
+	}
+}
+
+func TestHeap(t *testing.T) {
+	h := NewHeap(func(a, b int) bool { return a < b })
+	nodes := make(map[int]*HeapNode[int])
+	for _, v := range []int{5, 3, 8, 1, 9, 2, 7} {
+		nodes[v] = h.Push(v)
+	}
+	if h.Peek().Value != 1 {
+		t.Errorf("Expected min 1, got %d", h.Peek().Value)
+	}
+
+	// Raise the minimum, lower a leaf, and remove an interior value.
+	nodes[1].Value = 10
+	h.Fix(nodes[1])
+	nodes[9].Value = 0
+	h.Fix(nodes[9])
+	if !h.Remove(nodes[5]) || h.Remove(nodes[5]) {
+		t.Error("Expected Remove to succeed exactly once")
+	}
+
+	other := NewHeap(func(a, b int) bool { return a < b })
+	foreign := other.Push(-1)
+	if h.Remove(foreign) {
+		t.Error("Expected Remove of a foreign handle to fail")
+	}
+	h.Fix(foreign)
+
+	var got []int
+	for h.Len() > 0 {
+		v, _ := h.Pop()
+		got = append(got, v)
+	}
+	if want := []int{0, 2, 3, 7, 8, 10}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if _, ok := h.Pop(); ok || h.Peek() != nil {
+		t.Error("Expected empty heap")
+	}
+	if other.Len() != 1 {
+		t.Error("Foreign heap should be untouched")
+	}
+}
+
+func TestHeapRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	h := NewHeap(func(a, b int) bool { return a < b })
+	var live []*HeapNode[int]
+
+	for i := 0; i < 2000; i++ {
+		switch rnd.Intn(4) {
+		case 0, 1:
+			live = append(live, h.Push(rnd.Intn(1000)))
+		case 2:
+			if len(live) > 0 {
+				j := rnd.Intn(len(live))
+				h.Remove(live[j])
+				live = slices.Delete(live, j, j+1)
+			}
+		case 3:
+			if len(live) > 0 {
+				node := live[rnd.Intn(len(live))]
+				node.Value = rnd.Intn(1000)
+				h.Fix(node)
+			}
+		}
+	}
+
+	var want []int
+	for _, node := range live {
+		want = append(want, node.Value)
+	}
+	slices.Sort(want)
+	var got []int
+	for h.Len() > 0 {
+		v, _ := h.Pop()
+		got = append(got, v)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Heap order diverged from sorted values")
+	}
+}
+
+func BenchmarkContainers(b *testing.B) {
+	b.Run("Deque/PushPop", func(b *testing.B) {
+		d := NewDeque[int]()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d.PushBack(i)
+			if d.Len() > 1024 {
+				d.PopFront()
+			}
+		}
+	})
+
+	b.Run("Ring/Push", func(b *testing.B) {
+		r := NewRing[int](1024)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r.Push(i)
+		}
+	})
+
+	b.Run("Heap/PushPop", func(b *testing.B) {
+		h := NewHeap(func(a, b int) bool { return a < b })
+		rnd := rand.New(rand.NewSource(1))
+		for i := 0; i < 1024; i++ {
+			h.Push(rnd.Int())
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.Push(rnd.Int())
+			h.Pop()
+		}
+	})
+
+	b.Run("Heap/Fix", func(b *testing.B) {
+		h := NewHeap(func(a, b int) bool { return a < b })
+		rnd := rand.New(rand.NewSource(1))
+		nodes := make([]*HeapNode[int], 1024)
+		for i := range nodes {
+			nodes[i] = h.Push(rnd.Int())
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			node := nodes[i%len(nodes)]
+			node.Value = rnd.Int()
+			h.Fix(node)
+		}
+	})
+}
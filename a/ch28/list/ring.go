@@ -0,0 +1,91 @@
+package list
+
+import "iter"
+
+// --- Ring Buffer ---
+
+// Ring is a fixed-capacity FIFO buffer. Pushing onto a full ring overwrites
+// the oldest value.
+type Ring[T any] struct {
+	buf  []T
+	head int // index of the oldest value
+	size int
+}
+
+// NewRing creates an empty ring holding at most capacity values.
+// It returns nil if capacity is not positive.
+func NewRing[T any](capacity int) *Ring[T] {
+	if capacity <= 0 {
+		return nil
+	}
+	return &Ring[T]{buf: make([]T, capacity)}
+}
+
+// Len returns the number of values in the ring.
+func (r *Ring[T]) Len() int { return r.size }
+
+// Cap returns the maximum number of values the ring can hold.
+func (r *Ring[T]) Cap() int { return len(r.buf) }
+
+// Full reports whether the next Push will overwrite a value.
+func (r *Ring[T]) Full() bool { return r.size == len(r.buf) }
+
+// Push adds a value as the newest element. If the ring was full, the oldest
+// value is overwritten and returned with overwritten set to true.
+func (r *Ring[T]) Push(value T) (old T, overwritten bool) {
+	if r.Full() {
+		old = r.buf[r.head]
+		r.buf[r.head] = value
+		r.head = (r.head + 1) % len(r.buf)
+		return old, true
+	}
+	r.buf[(r.head+r.size)%len(r.buf)] = value
+	r.size++
+	return old, false
+}
+
+// Pop removes and returns the oldest value. ok is false if the ring is empty.
+func (r *Ring[T]) Pop() (value T, ok bool) {
+	if r.size == 0 {
+		return value, false
+	}
+	var zero T
+	value, r.buf[r.head] = r.buf[r.head], zero
+	r.head = (r.head + 1) % len(r.buf)
+	r.size--
+	return value, true
+}
+
+// Peek returns the oldest value without removing it.
+func (r *Ring[T]) Peek() (value T, ok bool) {
+	if r.size == 0 {
+		return value, false
+	}
+	return r.buf[r.head], true
+}
+
+// At returns the i-th oldest value. It panics if i is out of range.
+func (r *Ring[T]) At(i int) T {
+	if i < 0 || i >= r.size {
+		panic("list: Ring index out of range")
+	}
+	return r.buf[(r.head+i)%len(r.buf)]
+}
+
+// Clear removes all values.
+func (r *Ring[T]) Clear() {
+	clear(r.buf)
+	r.head = 0
+	r.size = 0
+}
+
+// All returns an iterator over the values from oldest to newest.
+func (r *Ring[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < r.size; i++ {
+			if !yield(r.buf[(r.head+i)%len(r.buf)]) {
+				return
+			}
+		}
+	}
+}
@@ -12,6 +12,7 @@ type LRUCache struct {
 	list     *list.DoublyLinkedList[cachePayload]
 	hits     uint64
 	misses   uint64
+	onEvict  func(key string, value interface{})
 }
 
 func NewLRUCache(capacity int) *LRUCache {
@@ -21,7 +22,7 @@ func NewLRUCache(capacity int) *LRUCache {
 	return &LRUCache{
 		capacity: capacity,
 		cache:    make(map[string]*list.DoublyNode[cachePayload]),
-		list:     list.NewDoubly[cachePayload](),
+		list:     list.NewDoublyWithSlab(list.NewSlab[cachePayload](0)),
 	}
 }
 
@@ -45,35 +46,57 @@ func (c *LRUCache) Put(key string, value interface{}) {
 		c.list.MoveToFront(node)
 		return
 	}
-	if c.list.Len >= c.capacity {
-		tail := c.list.Back()
-		if tail != nil {
-			delete(c.cache, tail.Value.key)
-			c.list.Remove(tail)
-		}
+	if c.list.Len() >= c.capacity {
+		c.evictOne()
 	}
 	node := c.list.PushFront(cachePayload{key: key, value: value})
 	c.cache[key] = node
 }
 
+// evictOne removes the least recently used entry.
+func (c *LRUCache) evictOne() bool {
+	tail := c.list.Back()
+	if tail == nil {
+		return false
+	}
+	payload := tail.Value
+	delete(c.cache, payload.key)
+	c.list.Free(tail)
+	c.evicted(payload.key, payload.value)
+	return true
+}
+
+func (c *LRUCache) contains(key string) bool {
+	_, ok := c.cache[key]
+	return ok
+}
+
+func (c *LRUCache) setEvictHook(fn func(key string, value interface{})) { c.onEvict = fn }
+
+func (c *LRUCache) evicted(key string, value interface{}) {
+	if c.onEvict != nil {
+		c.onEvict(key, value)
+	}
+}
+
 func (c *LRUCache) Delete(key string) bool {
 	node, ok := c.cache[key]
 	if !ok {
 		return false
 	}
 	delete(c.cache, key)
-	c.list.Remove(node)
+	c.list.Free(node)
 	return true
 }
 
 func (c *LRUCache) Clear() {
 	c.cache = make(map[string]*list.DoublyNode[cachePayload])
-	c.list = list.NewDoubly[cachePayload]()
+	c.list = list.NewDoublyWithSlab(list.NewSlab[cachePayload](0))
 	c.hits = 0
 	c.misses = 0
 }
 
-func (c *LRUCache) Size() int { return c.list.Len }
+func (c *LRUCache) Size() int { return c.list.Len() }
 
 func (c *LRUCache) Capacity() int { return c.capacity }
 
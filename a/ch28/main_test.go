@@ -2,9 +2,12 @@ package cache
 
 import (
 	"fmt"
+	"math/rand"
 	"sync"
 	"testing"
 	"time"
+
+	"go-interview/a/ch28/workload"
 )
 
 // TestLRUCache tests the LRU cache implementation
@@ -332,6 +335,207 @@ func TestThreadSafeCache(t *testing.T) {
 	})
 }
 
+// TestConcurrentCache tests the read-optimized concurrent cache
+func TestConcurrentCache(t *testing.T) {
+	t.Run("Basic Operations", func(t *testing.T) {
+		cache := NewConcurrentCache(LRU, 2)
+		if cache == nil {
+			t.Fatal("NewConcurrentCache returned nil")
+		}
+		if NewConcurrentCache(LRU, 0) != nil {
+			t.Error("Expected nil for zero capacity")
+		}
+
+		cache.Put("a", 1)
+		cache.Put("a", 2)
+		value, found := cache.Get("a")
+		if !found || value != 2 {
+			t.Errorf("Expected (2, true), got (%v, %v)", value, found)
+		}
+		if _, found := cache.Get("b"); found {
+			t.Error("Expected miss for non-existent key")
+		}
+		if cache.HitRate() != 0.5 {
+			t.Errorf("Expected hit rate 0.5, got %f", cache.HitRate())
+		}
+
+		if !cache.Delete("a") || cache.Delete("a") {
+			t.Error("Expected Delete to report existence exactly once")
+		}
+		if cache.Size() != 0 {
+			t.Errorf("Expected size 0, got %d", cache.Size())
+		}
+	})
+
+	t.Run("Buffered Reads Drive Eviction", func(t *testing.T) {
+		for _, policy := range []CachePolicy{LRU, LFU} {
+			cache := NewConcurrentCache(policy, 2)
+			cache.Put("a", 1)
+			cache.Put("b", 2)
+			cache.Get("a")
+			cache.Put("c", 3) // drains the read of "a" before evicting
+
+			if _, found := cache.Get("b"); found {
+				t.Errorf("%s: expected 'b' to be evicted", policy)
+			}
+			if _, found := cache.Get("a"); !found {
+				t.Errorf("%s: expected 'a' to survive", policy)
+			}
+			if cache.Size() != 2 {
+				t.Errorf("%s: expected size 2, got %d", policy, cache.Size())
+			}
+		}
+	})
+
+	t.Run("Many Reads Between Writes", func(t *testing.T) {
+		cache := NewConcurrentCache(LFU, 2)
+		cache.Put("a", 1)
+		cache.Put("b", 2)
+		// Far more reads than a single buffer holds forces inline drains.
+		for i := 0; i < 10*readBufferSize; i++ {
+			cache.Get("a")
+		}
+		cache.Put("c", 3)
+		if _, found := cache.Get("a"); !found {
+			t.Error("Expected frequently read 'a' to survive")
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		cache := NewConcurrentCache(FIFO, 2)
+		cache.Put("a", 1)
+		cache.Get("a")
+		cache.Clear()
+		if cache.Size() != 0 || cache.HitRate() != 0 {
+			t.Errorf("Expected empty cache after clear, got size %d hit rate %f", cache.Size(), cache.HitRate())
+		}
+		if _, found := cache.Get("a"); found {
+			t.Error("Expected cache to be empty after clear")
+		}
+	})
+}
+
+// TestPartitionedCache tests the multi-tenant cache
+func TestPartitionedCache(t *testing.T) {
+	t.Run("Quota Isolation", func(t *testing.T) {
+		cache := NewPartitionedCache(100, 2, LRU)
+		cache.Put("quiet", "a", 1)
+		cache.Put("quiet", "b", 2)
+
+		// A noisy tenant only churns through its own quota.
+		for i := 0; i < 50; i++ {
+			cache.Put("noisy", fmt.Sprintf("key-%d", i), i)
+		}
+
+		for _, key := range []string{"a", "b"} {
+			if _, found := cache.Get("quiet", key); !found {
+				t.Errorf("Expected quiet tenant to keep %q", key)
+			}
+		}
+		stats, _ := cache.Stats("noisy")
+		if stats.Size != 2 || stats.Evictions != 48 {
+			t.Errorf("Expected noisy size 2 with 48 evictions, got %+v", stats)
+		}
+	})
+
+	t.Run("Global Ceiling", func(t *testing.T) {
+		cache := NewPartitionedCache(4, 0, LRU)
+		if err := cache.AddNamespace("big", 4, LRU); err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.AddNamespace("small", 2, LRU); err != nil {
+			t.Fatal(err)
+		}
+
+		cache.Put("big", "b1", 1)
+		cache.Put("big", "b2", 2)
+		cache.Put("big", "b3", 3)
+		cache.Put("small", "s1", 1)
+		// At the ceiling: small uses 1/2 of its quota, big 3/4, so big yields
+		// its least recently used entry.
+		cache.Put("small", "s2", 2)
+
+		if cache.Size() != 4 {
+			t.Errorf("Expected total size 4, got %d", cache.Size())
+		}
+		if _, found := cache.Get("big", "b1"); found {
+			t.Error("Expected 'b1' to be evicted by the global ceiling")
+		}
+		if _, found := cache.Get("small", "s2"); !found {
+			t.Error("Expected 's2' to be stored")
+		}
+	})
+
+	t.Run("Per Namespace Policy", func(t *testing.T) {
+		cache := NewPartitionedCache(10, 2, LRU)
+		if err := cache.AddNamespace("fifo", 2, FIFO); err != nil {
+			t.Fatal(err)
+		}
+		for _, ns := range []string{"lru", "fifo"} {
+			cache.Put(ns, "a", 1)
+			cache.Put(ns, "b", 2)
+			cache.Get(ns, "a")
+			cache.Put(ns, "c", 3)
+		}
+
+		if _, found := cache.Get("lru", "a"); !found {
+			t.Error("Expected LRU namespace to keep recently read 'a'")
+		}
+		if _, found := cache.Get("fifo", "a"); found {
+			t.Error("Expected FIFO namespace to evict 'a'")
+		}
+		if stats, _ := cache.Stats("fifo"); stats.Policy != FIFO {
+			t.Errorf("Expected FIFO policy, got %v", stats.Policy)
+		}
+	})
+
+	t.Run("Stats And Clear Namespace", func(t *testing.T) {
+		cache := NewPartitionedCache(10, 5, LFU)
+		tenant := cache.Namespace("tenant")
+		tenant.Put("a", 1)
+		tenant.Get("a")
+		tenant.Get("missing")
+		cache.Put("other", "x", 1)
+
+		stats, ok := cache.Stats("tenant")
+		if !ok || stats.Hits != 1 || stats.Misses != 1 || stats.HitRate != 0.5 || stats.Capacity != 5 {
+			t.Errorf("Unexpected stats %+v", stats)
+		}
+
+		if err := cache.ClearNamespace("tenant"); err != nil {
+			t.Fatal(err)
+		}
+		if tenant.Size() != 0 || tenant.HitRate() != 0 {
+			t.Errorf("Expected empty namespace after clear, got size %d", tenant.Size())
+		}
+		if _, found := cache.Get("other", "x"); !found {
+			t.Error("ClearNamespace should not touch other namespaces")
+		}
+		if err := cache.ClearNamespace("unknown"); err != ErrNamespaceNotFound {
+			t.Errorf("Expected ErrNamespaceNotFound, got %v", err)
+		}
+
+		names := cache.Namespaces()
+		if len(names) != 2 || names[0] != "other" || names[1] != "tenant" {
+			t.Errorf("Expected [other tenant], got %v", names)
+		}
+	})
+
+	t.Run("Add Namespace Errors", func(t *testing.T) {
+		cache := NewPartitionedCache(10, 5, LRU)
+		if err := cache.AddNamespace("a", 0, LRU); err != ErrInvalidCapacity {
+			t.Errorf("Expected ErrInvalidCapacity, got %v", err)
+		}
+		cache.AddNamespace("a", 1, LRU)
+		if err := cache.AddNamespace("a", 1, LRU); err != ErrNamespaceExists {
+			t.Errorf("Expected ErrNamespaceExists, got %v", err)
+		}
+		if NewPartitionedCache(0, 1, LRU) != nil {
+			t.Error("Expected nil for zero global capacity")
+		}
+	})
+}
+
 // TestCacheFactory tests the factory functions
 func TestCacheFactory(t *testing.T) {
 	t.Run("NewCache", func(t *testing.T) {
@@ -496,6 +700,130 @@ func BenchmarkCacheOperations(b *testing.B) {
 	})
 }
 
+// BenchmarkSteadyStatePut measures inserts into a full cache, where every Put
+// evicts one entry. Keys are pre-formatted so only the cache allocates.
+func BenchmarkSteadyStatePut(b *testing.B) {
+	const capacity = 1000
+	keys := make([]string, capacity*4)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+
+	for _, policy := range Policies {
+		b.Run(policy.String(), func(b *testing.B) {
+			cache := NewCache(policy, capacity)
+			for _, key := range keys {
+				cache.Put(key, nil)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Put(keys[i%len(keys)], nil)
+			}
+		})
+	}
+}
+
+// BenchmarkPolicyWorkloads replays skewed and scan-heavy key streams against
+// every policy as a read-through cache and reports the hit rate alongside
+// ns/op and allocs/op.
+func BenchmarkPolicyWorkloads(b *testing.B) {
+	const (
+		keySpace = 10000
+		capacity = 1000
+		traceLen = 1 << 16
+	)
+
+	workloads := []struct {
+		name string
+		gen  workload.Generator
+	}{
+		{"Uniform", workload.NewUniform(1, keySpace)},
+		{"Zipf", workload.NewZipf(1, keySpace, 1.1)},
+		{"Hotspot", workload.NewHotspot(1, keySpace, 0.1, 0.9)},
+		{"Scan", workload.NewScan(capacity + capacity/2)},
+	}
+
+	for _, w := range workloads {
+		trace := workload.Take(w.gen, traceLen)
+		for _, policy := range Policies {
+			b.Run(fmt.Sprintf("%s/%s", w.name, policy), func(b *testing.B) {
+				cache := NewCache(policy, capacity)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					key := trace[i%traceLen]
+					if _, found := cache.Get(key); !found {
+						cache.Put(key, nil)
+					}
+				}
+				b.ReportMetric(cache.HitRate(), "hit-rate")
+			})
+		}
+	}
+
+	mixed := workload.NewMixed(1, workload.NewZipf(1, keySpace, 1.1), 0.1)
+	accesses := make([]workload.Access, traceLen)
+	for i := range accesses {
+		accesses[i] = mixed.Next()
+	}
+	for _, policy := range Policies {
+		b.Run(fmt.Sprintf("Mixed/%s", policy), func(b *testing.B) {
+			cache := NewCache(policy, capacity)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				access := accesses[i%traceLen]
+				if access.Write {
+					cache.Put(access.Key, nil)
+					continue
+				}
+				if _, found := cache.Get(access.Key); !found {
+					cache.Put(access.Key, nil)
+				}
+			}
+			b.ReportMetric(cache.HitRate(), "hit-rate")
+		})
+	}
+}
+
+// BenchmarkParallelGet compares read scaling of the mutex wrapper against the
+// buffered concurrent cache. Run with -cpu 1,2,4,8 to see the difference.
+func BenchmarkParallelGet(b *testing.B) {
+	const capacity = 1000
+	trace := workload.Take(workload.NewZipf(1, capacity*2, 1.1), 1<<16)
+
+	for _, policy := range Policies {
+		caches := []struct {
+			name  string
+			cache Cache
+		}{
+			{"ThreadSafe", NewThreadSafeCacheWithPolicy(policy, capacity)},
+			{"Concurrent", NewConcurrentCache(policy, capacity)},
+		}
+		for _, c := range caches {
+			for i := 0; i < capacity; i++ {
+				c.cache.Put(trace[i], i)
+			}
+			b.Run(fmt.Sprintf("%s/%s", c.name, policy), func(b *testing.B) {
+				b.ReportAllocs()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					i := rand.Intn(len(trace))
+					for pb.Next() {
+						key := trace[i%len(trace)]
+						if _, found := c.cache.Get(key); !found && i%8 == 0 {
+							c.cache.Put(key, i)
+						}
+						i++
+					}
+				})
+				b.ReportMetric(c.cache.HitRate(), "hit-rate")
+			})
+		}
+	}
+}
+
 // TestCacheComparison compares different cache policies
 func TestCacheComparison(t *testing.T) {
 	const capacity = 3
@@ -0,0 +1,276 @@
+package cache
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+//
+// Partitioned (Multi-Tenant) Cache
+//
+// PartitionedCache gives every namespace its own policy cache sized to the
+// namespace's quota, so a noisy tenant can only evict its own entries. A
+// global ceiling caps the total number of entries across namespaces; when a
+// write would exceed it, the namespace using the largest share of its quota
+// gives up an entry chosen by its own policy.
+//
+
+var (
+	ErrNamespaceExists   = errors.New("namespace already exists")
+	ErrInvalidCapacity   = errors.New("capacity must be positive")
+	ErrNamespaceNotFound = errors.New("namespace not found")
+)
+
+// NamespaceStats is a point-in-time snapshot of a namespace's usage.
+type NamespaceStats struct {
+	Policy    CachePolicy
+	Size      int
+	Capacity  int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	HitRate   float64
+}
+
+type partition struct {
+	policy    CachePolicy
+	cache     policyCache
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+func newPartition(policy CachePolicy, capacity int) *partition {
+	p := &partition{policy: policy, cache: NewCache(policy, capacity).(policyCache)}
+	p.cache.setEvictHook(func(string, interface{}) { p.evictions++ })
+	return p
+}
+
+// usage is the fraction of the namespace's quota currently in use.
+func (p *partition) usage() float64 {
+	return float64(p.cache.Size()) / float64(p.cache.Capacity())
+}
+
+type PartitionedCache struct {
+	mu            sync.Mutex
+	capacity      int
+	defaultQuota  int
+	defaultPolicy CachePolicy
+	partitions    map[string]*partition
+}
+
+// NewPartitionedCache creates a thread-safe multi-tenant cache holding at most
+// capacity entries in total. Namespaces that are written to without being
+// added first get defaultQuota entries (capacity if non-positive) and
+// defaultPolicy.
+func NewPartitionedCache(capacity, defaultQuota int, defaultPolicy CachePolicy) *PartitionedCache {
+	if capacity <= 0 {
+		return nil
+	}
+	if defaultQuota <= 0 {
+		defaultQuota = capacity
+	}
+	return &PartitionedCache{
+		capacity:      capacity,
+		defaultQuota:  defaultQuota,
+		defaultPolicy: defaultPolicy,
+		partitions:    make(map[string]*partition),
+	}
+}
+
+// AddNamespace registers a namespace with its own quota and policy.
+func (c *PartitionedCache) AddNamespace(name string, quota int, policy CachePolicy) error {
+	if quota <= 0 {
+		return ErrInvalidCapacity
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.partitions[name]; ok {
+		return ErrNamespaceExists
+	}
+	c.partitions[name] = newPartition(policy, quota)
+	return nil
+}
+
+// Namespace returns a Cache view scoped to a single namespace, creating the
+// namespace with the default quota and policy if it doesn't exist yet.
+func (c *PartitionedCache) Namespace(name string) Cache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.partitionLocked(name)
+	return &namespaceCache{parent: c, name: name}
+}
+
+// partitionLocked returns the named partition, creating it with defaults.
+// Must be called with c.mu held.
+func (c *PartitionedCache) partitionLocked(name string) *partition {
+	p, ok := c.partitions[name]
+	if !ok {
+		p = newPartition(c.defaultPolicy, c.defaultQuota)
+		c.partitions[name] = p
+	}
+	return p
+}
+
+// Namespaces returns the names of all known namespaces in sorted order.
+func (c *PartitionedCache) Namespaces() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.partitions))
+	for name := range c.partitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *PartitionedCache) Get(namespace, key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.partitions[namespace]
+	if !ok {
+		return nil, false
+	}
+	value, found := p.cache.Get(key)
+	if found {
+		p.hits++
+	} else {
+		p.misses++
+	}
+	return value, found
+}
+
+func (c *PartitionedCache) Put(namespace, key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := c.partitionLocked(namespace)
+
+	// Only a new key in a namespace below its quota grows the total; at quota
+	// the namespace's own policy makes room.
+	grows := !p.cache.contains(key) && p.cache.Size() < p.cache.Capacity()
+	if grows && c.sizeLocked() >= c.capacity {
+		c.victimLocked().cache.evictOne()
+	}
+	p.cache.Put(key, value)
+}
+
+// victimLocked picks the namespace using the largest share of its quota,
+// preferring the larger namespace on ties.
+// Must be called with c.mu held.
+func (c *PartitionedCache) victimLocked() *partition {
+	var victim *partition
+	for _, p := range c.partitions {
+		if p.cache.Size() == 0 {
+			continue
+		}
+		if victim == nil || p.usage() > victim.usage() ||
+			(p.usage() == victim.usage() && p.cache.Size() > victim.cache.Size()) {
+			victim = p
+		}
+	}
+	return victim
+}
+
+func (c *PartitionedCache) Delete(namespace, key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.partitions[namespace]
+	if !ok {
+		return false
+	}
+	return p.cache.Delete(key)
+}
+
+// ClearNamespace removes every entry of a namespace and resets its stats,
+// leaving its quota and policy in place.
+func (c *PartitionedCache) ClearNamespace(namespace string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.partitions[namespace]
+	if !ok {
+		return ErrNamespaceNotFound
+	}
+	p.cache.Clear()
+	p.hits, p.misses, p.evictions = 0, 0, 0
+	return nil
+}
+
+// Clear removes every entry from every namespace.
+func (c *PartitionedCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range c.partitions {
+		p.cache.Clear()
+		p.hits, p.misses, p.evictions = 0, 0, 0
+	}
+}
+
+// Stats returns a snapshot of one namespace's usage.
+func (c *PartitionedCache) Stats(namespace string) (NamespaceStats, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.partitions[namespace]
+	if !ok {
+		return NamespaceStats{}, false
+	}
+	stats := NamespaceStats{
+		Policy:    p.policy,
+		Size:      p.cache.Size(),
+		Capacity:  p.cache.Capacity(),
+		Hits:      p.hits,
+		Misses:    p.misses,
+		Evictions: p.evictions,
+	}
+	if total := p.hits + p.misses; total > 0 {
+		stats.HitRate = float64(p.hits) / float64(total)
+	}
+	return stats, true
+}
+
+// Size returns the number of entries across all namespaces.
+func (c *PartitionedCache) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sizeLocked()
+}
+
+func (c *PartitionedCache) sizeLocked() int {
+	size := 0
+	for _, p := range c.partitions {
+		size += p.cache.Size()
+	}
+	return size
+}
+
+// Capacity returns the global ceiling.
+func (c *PartitionedCache) Capacity() int { return c.capacity }
+
+// namespaceCache adapts one namespace of a PartitionedCache to Cache.
+type namespaceCache struct {
+	parent *PartitionedCache
+	name   string
+}
+
+func (n *namespaceCache) Get(key string) (interface{}, bool) { return n.parent.Get(n.name, key) }
+
+func (n *namespaceCache) Put(key string, value interface{}) { n.parent.Put(n.name, key, value) }
+
+func (n *namespaceCache) Delete(key string) bool { return n.parent.Delete(n.name, key) }
+
+func (n *namespaceCache) Clear() { n.parent.ClearNamespace(n.name) }
+
+func (n *namespaceCache) Size() int {
+	stats, _ := n.parent.Stats(n.name)
+	return stats.Size
+}
+
+func (n *namespaceCache) Capacity() int {
+	stats, _ := n.parent.Stats(n.name)
+	return stats.Capacity
+}
+
+func (n *namespaceCache) HitRate() float64 {
+	stats, _ := n.parent.Stats(n.name)
+	return stats.HitRate
+}
@@ -15,9 +15,11 @@ func NewThreadSafeCache(cache Cache) *ThreadSafeCache {
 	return &ThreadSafeCache{cache: cache}
 }
 
+// Get takes the write lock because every policy updates hit counters and
+// most reorder their bookkeeping on reads.
 func (c *ThreadSafeCache) Get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.cache.Get(key)
 }
 
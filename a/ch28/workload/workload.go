@@ -0,0 +1,146 @@
+// Package workload provides seeded key-stream generators for exercising cache
+// policies with realistic access patterns.
+package workload
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// Generator produces an endless stream of keys.
+type Generator interface {
+	Next() string
+}
+
+// keySpace holds pre-formatted keys so generators don't allocate per call.
+type keySpace []string
+
+func newKeySpace(n int) keySpace {
+	if n <= 0 {
+		n = 1
+	}
+	keys := make(keySpace, n)
+	for i := range keys {
+		keys[i] = "key-" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+// --- Uniform ---
+
+type uniform struct {
+	keys keySpace
+	rnd  *rand.Rand
+}
+
+// NewUniform returns a generator picking each of n keys with equal probability.
+func NewUniform(seed int64, n int) Generator {
+	return &uniform{keys: newKeySpace(n), rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (g *uniform) Next() string { return g.keys[g.rnd.Intn(len(g.keys))] }
+
+// --- Zipf ---
+
+type zipf struct {
+	keys keySpace
+	zipf *rand.Zipf
+}
+
+// NewZipf returns a generator over n keys where the probability of key k is
+// proportional to 1/(k+1)^s. s must be greater than 1; smaller values are
+// clamped to 1.01. Larger s concentrates traffic on fewer keys.
+func NewZipf(seed int64, n int, s float64) Generator {
+	if s <= 1 {
+		s = 1.01
+	}
+	keys := newKeySpace(n)
+	rnd := rand.New(rand.NewSource(seed))
+	return &zipf{keys: keys, zipf: rand.NewZipf(rnd, s, 1, uint64(len(keys)-1))}
+}
+
+func (g *zipf) Next() string { return g.keys[g.zipf.Uint64()] }
+
+// --- Hotspot ---
+
+type hotspot struct {
+	keys    keySpace
+	hot     int
+	hotProb float64
+	rnd     *rand.Rand
+}
+
+// NewHotspot returns a generator over n keys where the first hotFraction of
+// the key space receives hotProb of the accesses and the rest is uniform.
+func NewHotspot(seed int64, n int, hotFraction, hotProb float64) Generator {
+	keys := newKeySpace(n)
+	hot := int(float64(len(keys)) * hotFraction)
+	if hot < 1 {
+		hot = 1
+	}
+	if hot > len(keys) {
+		hot = len(keys)
+	}
+	return &hotspot{keys: keys, hot: hot, hotProb: hotProb, rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (g *hotspot) Next() string {
+	if g.hot == len(g.keys) || g.rnd.Float64() < g.hotProb {
+		return g.keys[g.rnd.Intn(g.hot)]
+	}
+	return g.keys[g.hot+g.rnd.Intn(len(g.keys)-g.hot)]
+}
+
+// --- Sequential Scan ---
+
+type scan struct {
+	keys keySpace
+	pos  int
+}
+
+// NewScan returns a generator that walks n keys in order and wraps around.
+// A scan larger than the cache defeats recency-based policies entirely.
+func NewScan(n int) Generator {
+	return &scan{keys: newKeySpace(n)}
+}
+
+func (g *scan) Next() string {
+	key := g.keys[g.pos]
+	g.pos = (g.pos + 1) % len(g.keys)
+	return key
+}
+
+// --- Read/Write Mix ---
+
+// Access is a single operation in a mixed workload.
+type Access struct {
+	Key   string
+	Write bool
+}
+
+// Mixed turns a key generator into a stream of reads and writes.
+type Mixed struct {
+	gen        Generator
+	writeRatio float64
+	rnd        *rand.Rand
+}
+
+// NewMixed returns a stream where each access is a write with probability
+// writeRatio and a read otherwise.
+func NewMixed(seed int64, gen Generator, writeRatio float64) *Mixed {
+	return &Mixed{gen: gen, writeRatio: writeRatio, rnd: rand.New(rand.NewSource(seed))}
+}
+
+// Next returns the next access in the stream.
+func (m *Mixed) Next() Access {
+	return Access{Key: m.gen.Next(), Write: m.rnd.Float64() < m.writeRatio}
+}
+
+// Take collects the next n keys from g.
+func Take(g Generator, n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = g.Next()
+	}
+	return keys
+}
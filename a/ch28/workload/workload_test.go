@@ -0,0 +1,106 @@
+package workload
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestDeterministicSeeds(t *testing.T) {
+	generators := map[string]func() Generator{
+		"Uniform": func() Generator { return NewUniform(42, 100) },
+		"Zipf":    func() Generator { return NewZipf(42, 100, 1.2) },
+		"Hotspot": func() Generator { return NewHotspot(42, 100, 0.1, 0.9) },
+	}
+
+	for name, newGen := range generators {
+		t.Run(name, func(t *testing.T) {
+			a := Take(newGen(), 1000)
+			b := Take(newGen(), 1000)
+			for i := range a {
+				if a[i] != b[i] {
+					t.Fatalf("Streams with the same seed diverged at %d: %s != %s", i, a[i], b[i])
+				}
+			}
+		})
+	}
+}
+
+func TestZipfSkew(t *testing.T) {
+	counts := make(map[string]int)
+	for _, key := range Take(NewZipf(1, 1000, 1.5), 100000) {
+		counts[key]++
+	}
+
+	if counts["key-0"] <= counts["key-1"] || counts["key-1"] <= counts["key-10"] {
+		t.Errorf("Expected decreasing popularity, got key-0=%d key-1=%d key-10=%d",
+			counts["key-0"], counts["key-1"], counts["key-10"])
+	}
+	if counts["key-0"] < 100000/4 {
+		t.Errorf("Expected the top key to take a large share, got %d", counts["key-0"])
+	}
+}
+
+func TestHotspot(t *testing.T) {
+	const total = 100000
+	hot := 0
+	for _, key := range Take(NewHotspot(1, 100, 0.1, 0.9), total) {
+		id, err := strconv.Atoi(key[len("key-"):])
+		if err != nil {
+			t.Fatalf("Unexpected key %q", key)
+		}
+		if id < 10 {
+			hot++
+		}
+	}
+
+	ratio := float64(hot) / total
+	if ratio < 0.88 || ratio > 0.92 {
+		t.Errorf("Expected ~90%% of accesses in the hot set, got %.3f", ratio)
+	}
+}
+
+func TestScan(t *testing.T) {
+	keys := Take(NewScan(3), 7)
+	expected := []string{"key-0", "key-1", "key-2", "key-0", "key-1", "key-2", "key-0"}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, keys)
+			break
+		}
+	}
+}
+
+func TestMixed(t *testing.T) {
+	const total = 100000
+	mixed := NewMixed(7, NewUniform(7, 10), 0.2)
+
+	writes := 0
+	for i := 0; i < total; i++ {
+		if mixed.Next().Write {
+			writes++
+		}
+	}
+
+	ratio := float64(writes) / total
+	if ratio < 0.18 || ratio > 0.22 {
+		t.Errorf("Expected ~20%% writes, got %.3f", ratio)
+	}
+}
+
+func BenchmarkGenerators(b *testing.B) {
+	generators := map[string]Generator{
+		"Uniform": NewUniform(1, 10000),
+		"Zipf":    NewZipf(1, 10000, 1.1),
+		"Hotspot": NewHotspot(1, 10000, 0.2, 0.8),
+		"Scan":    NewScan(10000),
+	}
+
+	for name, gen := range generators {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				gen.Next()
+			}
+		})
+	}
+}
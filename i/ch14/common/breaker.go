@@ -0,0 +1,275 @@
+package common
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BreakerState represents the state of a client circuit breaker
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+// String returns the string representation of the state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "Closed"
+	case BreakerOpen:
+		return "Open"
+	case BreakerHalfOpen:
+		return "Half-Open"
+	default:
+		return "Unknown"
+	}
+}
+
+// BreakerConfig represents the configuration for client circuit breakers
+type BreakerConfig struct {
+	FailureThreshold int                                      // Consecutive failures that open a breaker, 5 by default
+	OpenTimeout      time.Duration                            // Time to wait before half-open, 30s by default
+	HalfOpenRequests int                                      // Concurrent probes allowed in half-open state, 1 by default
+	IsFailure        func(err error) bool                     // Reports whether an error counts against the target
+	OnStateChange    func(name string, from, to BreakerState) // State change callback
+	Now              func() time.Time                         // Time source, time.Now by default
+}
+
+// CircuitBreakers keeps one circuit breaker per target and method and exposes
+// them as gRPC client interceptors. Each breaker is a reduced port of the
+// Challenge 20 state machine, which lives in a separate main package and
+// cannot be imported: Closed -> Open after FailureThreshold consecutive
+// failures, Open -> Half-Open after OpenTimeout, and Half-Open -> Closed or
+// back to Open on the outcome of a probe. Rolling windows, backoff, success
+// thresholds and shared state are not ported.
+type CircuitBreakers struct {
+	config   BreakerConfig
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// NewCircuitBreakers creates an empty set of breakers sharing config
+func NewCircuitBreakers(config BreakerConfig) *CircuitBreakers {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = IsUnhealthy
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &CircuitBreakers{config: config, breakers: make(map[string]*breaker)}
+}
+
+// IsUnhealthy reports whether err signals an unhealthy target: Unavailable,
+// DeadlineExceeded or ResourceExhausted. Other codes mean the target answered.
+func IsUnhealthy(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// State returns the state of the breaker for target and method
+func (c *CircuitBreakers) State(target, method string) BreakerState {
+	b := c.get(target, method)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && !c.config.Now().Before(b.openUntil) {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// UnaryClientInterceptor guards unary calls. Calls to an open breaker fail
+// with codes.Unavailable without reaching the target.
+func (c *CircuitBreakers) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		b := c.get(targetOf(cc), method)
+		generation, err := b.allow()
+		if err != nil {
+			return err
+		}
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		b.done(generation, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor guards streaming calls. A stream counts as one
+// call, finished when RecvMsg returns an error (io.EOF meaning success), when
+// a stream without server streaming receives its single response, or when the
+// stream's context is done.
+func (c *CircuitBreakers) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		b := c.get(targetOf(cc), method)
+		generation, err := b.allow()
+		if err != nil {
+			return nil, err
+		}
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			b.done(generation, err)
+			return nil, err
+		}
+
+		s := &breakerStream{ClientStream: stream, single: !desc.ServerStreams}
+		s.finish = func(err error) {
+			s.once.Do(func() { b.done(generation, err) })
+		}
+		s.stop = context.AfterFunc(ctx, func() { s.finish(status.FromContextError(ctx.Err()).Err()) })
+		return s, nil
+	}
+}
+
+func (c *CircuitBreakers) get(target, method string) *breaker {
+	name := target + method
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.breakers[name]
+	if !ok {
+		b = &breaker{name: name, config: &c.config}
+		c.breakers[name] = b
+	}
+	return b
+}
+
+func targetOf(cc *grpc.ClientConn) string {
+	if cc == nil {
+		return ""
+	}
+	return cc.Target()
+}
+
+// breakerStream reports the outcome of a stream to its breaker
+type breakerStream struct {
+	grpc.ClientStream
+	single bool // the server sends one response, as in client streaming
+	once   sync.Once
+	finish func(err error)
+	stop   func() bool
+}
+
+func (s *breakerStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil && s.single {
+		s.stop()
+		s.finish(nil)
+	} else if err != nil {
+		s.stop()
+		if err == io.EOF {
+			s.finish(nil)
+		} else {
+			s.finish(err)
+		}
+	}
+	return err
+}
+
+// breaker is the per-target state machine
+type breaker struct {
+	name       string
+	config     *BreakerConfig
+	mu         sync.Mutex
+	state      BreakerState
+	failures   int       // consecutive failures
+	openUntil  time.Time // end of the current open period
+	generation uint64    // incremented on every state change
+	probes     int       // half-open calls in flight
+}
+
+// allow admits a call or returns a codes.Unavailable error, along with the
+// state generation the call was admitted in
+func (b *breaker) allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		now := b.config.Now()
+		if now.Before(b.openUntil) {
+			return 0, status.Errorf(codes.Unavailable, "circuit breaker open for %s: %d consecutive failures, retry in %v",
+				b.name, b.failures, b.openUntil.Sub(now).Round(time.Millisecond))
+		}
+		b.setState(BreakerHalfOpen)
+	}
+
+	if b.state == BreakerHalfOpen {
+		if b.probes >= b.config.HalfOpenRequests {
+			return 0, status.Errorf(codes.Unavailable, "circuit breaker half-open for %s: probe already in flight", b.name)
+		}
+		b.probes++
+	}
+	return b.generation, nil
+}
+
+// done records the outcome of a call admitted in generation. Outcomes of
+// calls admitted before the latest state change are discarded, so a slow
+// call from the closed state cannot decide a half-open probe.
+func (b *breaker) done(generation uint64, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+	if b.state == BreakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+	if status.Code(err) == codes.Canceled {
+		return // abandoned by the caller, says nothing about the target
+	}
+
+	if err != nil && b.config.IsFailure(err) {
+		b.failures++
+		switch b.state {
+		case BreakerClosed:
+			if b.failures >= b.config.FailureThreshold {
+				b.setState(BreakerOpen)
+			}
+		case BreakerHalfOpen:
+			b.setState(BreakerOpen)
+		}
+		return
+	}
+
+	b.failures = 0
+	if b.state == BreakerHalfOpen {
+		b.setState(BreakerClosed)
+	}
+}
+
+// setState transitions state. Must be called with b.mu held.
+func (b *breaker) setState(to BreakerState) {
+	if b.state == to {
+		return
+	}
+	from := b.state
+	b.state = to
+	b.generation++
+	b.probes = 0
+	if to == BreakerOpen {
+		b.openUntil = b.config.Now().Add(b.config.OpenTimeout)
+	}
+	if b.config.OnStateChange != nil {
+		b.config.OnStateChange(b.name, from, to)
+	}
+}
@@ -2,6 +2,10 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -15,6 +19,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 func TestUserService(t *testing.T) {
@@ -298,3 +303,294 @@ func TestInterceptors(t *testing.T) {
 		}
 	})
 }
+
+// fakeClientStream ends with err after yielding messages successful reads
+type fakeClientStream struct {
+	grpc.ClientStream
+	messages int
+	err      error
+}
+
+func (s *fakeClientStream) RecvMsg(m interface{}) error {
+	if s.messages > 0 {
+		s.messages--
+		return nil
+	}
+	return s.err
+}
+
+func TestCircuitBreakerInterceptor(t *testing.T) {
+	now := time.Unix(0, 0)
+	var mu sync.Mutex
+	var transitions []string
+	newBreakers := func() *common.CircuitBreakers {
+		return common.NewCircuitBreakers(common.BreakerConfig{
+			FailureThreshold: 2,
+			OpenTimeout:      time.Second,
+			Now:              func() time.Time { mu.Lock(); defer mu.Unlock(); return now },
+			OnStateChange: func(name string, from, to common.BreakerState) {
+				transitions = append(transitions, from.String()+"->"+to.String())
+			},
+		})
+	}
+	advance := func(d time.Duration) { mu.Lock(); now = now.Add(d); mu.Unlock() }
+
+	method := "/user.UserService/GetUser"
+	failWith := func(code codes.Code) grpc.UnaryInvoker {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return status.Error(code, "fail")
+		}
+	}
+	succeed := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+
+	t.Run("Unary", func(t *testing.T) {
+		transitions = nil
+		breakers := newBreakers()
+		intercept := breakers.UnaryClientInterceptor()
+		ctx := context.Background()
+
+		// Business errors mean the target answered and never open the breaker.
+		for i := 0; i < 3; i++ {
+			intercept(ctx, method, nil, nil, nil, failWith(codes.NotFound))
+		}
+		if s := breakers.State("", method); s != common.BreakerClosed {
+			t.Fatalf("Expected Closed after NotFound errors, got %v", s)
+		}
+
+		intercept(ctx, method, nil, nil, nil, failWith(codes.Unavailable))
+		intercept(ctx, method, nil, nil, nil, failWith(codes.DeadlineExceeded))
+		if s := breakers.State("", method); s != common.BreakerOpen {
+			t.Fatalf("Expected Open, got %v", s)
+		}
+
+		err := intercept(ctx, method, nil, nil, nil, succeed)
+		if status.Code(err) != codes.Unavailable || !strings.Contains(err.Error(), "circuit breaker open") {
+			t.Errorf("Expected descriptive Unavailable error, got %v", err)
+		}
+		if s := breakers.State("", "/user.UserService/Other"); s != common.BreakerClosed {
+			t.Errorf("Expected other methods to have their own breaker, got %v", s)
+		}
+
+		advance(time.Second)
+		if err := intercept(ctx, method, nil, nil, nil, succeed); err != nil {
+			t.Fatalf("Expected half-open probe to be admitted, got %v", err)
+		}
+		if s := breakers.State("", method); s != common.BreakerClosed {
+			t.Errorf("Expected Closed after successful probe, got %v", s)
+		}
+		want := "[Closed->Open Open->Half-Open Half-Open->Closed]"
+		if got := fmt.Sprint(transitions); got != want {
+			t.Errorf("Expected transitions %s, got %s", want, got)
+		}
+	})
+
+	t.Run("Resource Exhausted Probe", func(t *testing.T) {
+		breakers := newBreakers()
+		intercept := breakers.UnaryClientInterceptor()
+		ctx := context.Background()
+
+		intercept(ctx, method, nil, nil, nil, failWith(codes.ResourceExhausted))
+		intercept(ctx, method, nil, nil, nil, failWith(codes.ResourceExhausted))
+		advance(time.Second)
+		intercept(ctx, method, nil, nil, nil, failWith(codes.ResourceExhausted))
+		if s := breakers.State("", method); s != common.BreakerOpen {
+			t.Errorf("Expected failed probe to reopen the breaker, got %v", s)
+		}
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		breakers := newBreakers()
+		intercept := breakers.StreamClientInterceptor()
+		ctx := context.Background()
+		desc := &grpc.StreamDesc{ServerStreams: true}
+		streamer := func(err error) grpc.Streamer {
+			return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return &fakeClientStream{messages: 2, err: err}, nil
+			}
+		}
+		drain := func(s grpc.ClientStream) {
+			for s.RecvMsg(nil) == nil {
+			}
+		}
+
+		for i := 0; i < 2; i++ {
+			s, err := intercept(ctx, desc, nil, method, streamer(status.Error(codes.Unavailable, "reset")))
+			if err != nil {
+				t.Fatalf("Expected stream, got %v", err)
+			}
+			drain(s)
+		}
+		if _, err := intercept(ctx, desc, nil, method, streamer(io.EOF)); status.Code(err) != codes.Unavailable {
+			t.Fatalf("Expected Unavailable from open breaker, got %v", err)
+		}
+
+		advance(time.Second)
+		s, err := intercept(ctx, desc, nil, method, streamer(io.EOF))
+		if err != nil {
+			t.Fatalf("Expected probe stream, got %v", err)
+		}
+		drain(s)
+		if st := breakers.State("", method); st != common.BreakerClosed {
+			t.Errorf("Expected Closed after a stream ending in io.EOF, got %v", st)
+		}
+	})
+
+	t.Run("Client Stream Probe", func(t *testing.T) {
+		breakers := newBreakers()
+		intercept := breakers.StreamClientInterceptor()
+		ctx := context.Background()
+
+		invoke := breakers.UnaryClientInterceptor()
+		invoke(ctx, method, nil, nil, nil, failWith(codes.Unavailable))
+		invoke(ctx, method, nil, nil, nil, failWith(codes.Unavailable))
+		advance(time.Second)
+
+		// CloseAndRecv reads the single response and never calls RecvMsg again.
+		desc := &grpc.StreamDesc{ClientStreams: true}
+		s, err := intercept(ctx, desc, nil, method, func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{messages: 1, err: io.EOF}, nil
+		})
+		if err != nil {
+			t.Fatalf("Expected probe stream, got %v", err)
+		}
+		if err := s.RecvMsg(nil); err != nil {
+			t.Fatalf("Expected the response, got %v", err)
+		}
+		if st := breakers.State("", method); st != common.BreakerClosed {
+			t.Errorf("Expected Closed after a successful client stream probe, got %v", st)
+		}
+		if err := invoke(ctx, method, nil, nil, nil, succeed); err != nil {
+			t.Errorf("Expected calls to be admitted, got %v", err)
+		}
+	})
+
+	t.Run("Stream Cancelled", func(t *testing.T) {
+		breakers := newBreakers()
+		intercept := breakers.StreamClientInterceptor()
+		streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{err: io.EOF}, nil
+		}
+
+		invoke := breakers.UnaryClientInterceptor()
+		invoke(context.Background(), method, nil, nil, nil, failWith(codes.Unavailable))
+		invoke(context.Background(), method, nil, nil, nil, failWith(codes.Unavailable))
+		advance(time.Second)
+
+		// An abandoned probe stream must give its half-open slot back.
+		ctx, cancel := context.WithCancel(context.Background())
+		if _, err := intercept(ctx, &grpc.StreamDesc{}, nil, method, streamer); err != nil {
+			t.Fatalf("Expected probe stream, got %v", err)
+		}
+		cancel()
+
+		deadline := time.Now().Add(time.Second)
+		for {
+			err := invoke(context.Background(), method, nil, nil, nil, succeed)
+			if err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected the slot to be released, got %v", err)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+}
+
+func TestCircuitBreakerInterceptorStaleOutcome(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Unix(0, 0)
+	breakers := common.NewCircuitBreakers(common.BreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Second,
+		Now:              func() time.Time { mu.Lock(); defer mu.Unlock(); return now },
+	})
+	intercept := breakers.UnaryClientInterceptor()
+	method := "/user.UserService/GetUser"
+	ctx := context.Background()
+
+	// blocking returns an invoker that waits for release before returning err
+	blocking := func(err error) (grpc.UnaryInvoker, chan struct{}, chan struct{}) {
+		started, release := make(chan struct{}), make(chan struct{})
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			close(started)
+			<-release
+			return err
+		}, started, release
+	}
+
+	// A slow call admitted while Closed...
+	staleInvoker, staleStarted, staleRelease := blocking(nil)
+	staleDone := make(chan struct{})
+	go func() {
+		defer close(staleDone)
+		intercept(ctx, method, nil, nil, nil, staleInvoker)
+	}()
+	<-staleStarted
+
+	// ...outlives a trip and the start of a half-open probe.
+	intercept(ctx, method, nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "down")
+	})
+	mu.Lock()
+	now = now.Add(time.Second)
+	mu.Unlock()
+
+	probeInvoker, probeStarted, probeRelease := blocking(status.Error(codes.Unavailable, "still down"))
+	probeDone := make(chan struct{})
+	go func() {
+		defer close(probeDone)
+		intercept(ctx, method, nil, nil, nil, probeInvoker)
+	}()
+	<-probeStarted
+
+	close(staleRelease)
+	<-staleDone
+	if s := breakers.State("", method); s != common.BreakerHalfOpen {
+		t.Errorf("Expected the stale success to leave the breaker Half-Open, got %v", s)
+	}
+
+	close(probeRelease)
+	<-probeDone
+	if s := breakers.State("", method); s != common.BreakerOpen {
+		t.Errorf("Expected the failed probe to reopen the breaker, got %v", s)
+	}
+}
+
+func TestCircuitBreakerInterceptorWithConnection(t *testing.T) {
+	// Nothing listens on the target, so every call fails with Unavailable.
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve a port: %v", err)
+	}
+	target := listener.Addr().String()
+	listener.Close()
+
+	breakers := common.NewCircuitBreakers(common.BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(breakers.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+
+	method := "/user.UserService/GetUser"
+	for i := 0; i < 2; i++ {
+		err := conn.Invoke(context.Background(), method, &emptypb.Empty{}, &emptypb.Empty{})
+		if status.Code(err) != codes.Unavailable {
+			t.Fatalf("Expected Unavailable from the unreachable target, got %v", err)
+		}
+	}
+
+	if s := breakers.State(conn.Target(), method); s != common.BreakerOpen {
+		t.Fatalf("Expected Open, got %v", s)
+	}
+	err = conn.Invoke(context.Background(), method, &emptypb.Empty{}, &emptypb.Empty{})
+	if !strings.Contains(err.Error(), "circuit breaker open for "+target+method) {
+		t.Errorf("Expected the breaker to reject the call, got %v", err)
+	}
+}
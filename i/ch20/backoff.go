@@ -0,0 +1,49 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff computes how long to wait before the next attempt, given the
+// number of consecutive attempts already made (starting at 0)
+type Backoff interface {
+	Duration(attempt int) time.Duration
+}
+
+// ConstantBackoff always waits the same duration
+type ConstantBackoff time.Duration
+
+// Duration returns the constant delay
+func (b ConstantBackoff) Duration(int) time.Duration { return time.Duration(b) }
+
+// ExponentialBackoff grows the delay geometrically from Initial, capped at Max
+type ExponentialBackoff struct {
+	Initial    time.Duration // Delay for attempt 0
+	Max        time.Duration // Upper bound for any delay; 0 means uncapped
+	Multiplier float64       // Growth factor per attempt; 2 when not above 1
+	Jitter     float64       // Randomizes each delay by up to ±Jitter of itself (0-1)
+}
+
+// Duration returns Initial*Multiplier^attempt, jittered and capped at Max
+func (b ExponentialBackoff) Duration(attempt int) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+	limit := float64(math.MaxInt64)
+	if b.Max > 0 {
+		limit = float64(b.Max)
+	}
+
+	d := math.Min(float64(b.Initial)*math.Pow(multiplier, float64(max(attempt, 0))), limit)
+	if jitter := math.Min(b.Jitter, 1); jitter > 0 {
+		d += d * jitter * (2*rand.Float64() - 1)
+		d = math.Min(d, limit)
+	}
+	if d >= float64(math.MaxInt64) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
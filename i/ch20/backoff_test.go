@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	t.Run("Growth And Cap", func(t *testing.T) {
+		b := ExponentialBackoff{Initial: 100 * time.Millisecond, Max: time.Second}
+		want := []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			time.Second,
+			time.Second,
+		}
+		for attempt, w := range want {
+			if got := b.Duration(attempt); got != w {
+				t.Errorf("Attempt %d: expected %v, got %v", attempt, w, got)
+			}
+		}
+	})
+
+	t.Run("Multiplier", func(t *testing.T) {
+		b := ExponentialBackoff{Initial: time.Second, Multiplier: 3}
+		if got := b.Duration(2); got != 9*time.Second {
+			t.Errorf("Expected 9s, got %v", got)
+		}
+	})
+
+	t.Run("Jitter", func(t *testing.T) {
+		b := ExponentialBackoff{Initial: time.Second, Max: 3 * time.Second, Jitter: 0.5}
+		for i := 0; i < 100; i++ {
+			if got := b.Duration(0); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+				t.Fatalf("Expected 0.5s-1.5s, got %v", got)
+			}
+			if got := b.Duration(5); got < 1500*time.Millisecond || got > 3*time.Second {
+				t.Fatalf("Expected capped delay in 1.5s-3s, got %v", got)
+			}
+		}
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		b := ExponentialBackoff{Initial: time.Hour}
+		if got := b.Duration(1000); got != time.Duration(math.MaxInt64) {
+			t.Errorf("Expected saturation at the largest duration, got %v", got)
+		}
+	})
+}
+
+func TestConstantBackoff(t *testing.T) {
+	b := ConstantBackoff(time.Second)
+	if b.Duration(0) != time.Second || b.Duration(10) != time.Second {
+		t.Error("Expected a constant 1s delay")
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBulkheadFull is returned when a bulkhead has no free slot and the call
+// cannot queue, or gives up queueing after MaxWait
+var ErrBulkheadFull = errors.New("bulkhead is full")
+
+// BulkheadConfig represents the configuration for a Bulkhead
+type BulkheadConfig struct {
+	MaxConcurrent int           // Calls allowed to run at once, 10 by default
+	MaxWaiting    int           // Calls allowed to queue for a slot; 0 rejects immediately when full
+	MaxWait       time.Duration // How long a queued call waits for a slot; 0 waits until its context is done
+}
+
+// BulkheadMetrics represents the bulkhead metrics
+type BulkheadMetrics struct {
+	Active   int   // Calls currently running
+	Waiting  int   // Calls currently queued for a slot
+	Admitted int64 // Calls that obtained a slot
+	Rejected int64 // Calls that failed with ErrBulkheadFull
+}
+
+// Bulkhead caps the number of concurrent calls to a dependency so a slow
+// dependency cannot tie up every goroutine of the caller
+type Bulkhead struct {
+	config  BulkheadConfig
+	slots   chan struct{}
+	mutex   sync.Mutex
+	metrics BulkheadMetrics
+}
+
+// NewBulkhead creates a bulkhead with the given configuration
+func NewBulkhead(config BulkheadConfig) *Bulkhead {
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = 10
+	}
+	if config.MaxWaiting < 0 {
+		config.MaxWaiting = 0
+	}
+	return &Bulkhead{
+		config: config,
+		slots:  make(chan struct{}, config.MaxConcurrent),
+	}
+}
+
+// Execute runs operation once a slot is free. It fails with ErrBulkheadFull
+// if no slot can be obtained, or with ctx.Err() if ctx is done while queued.
+func (b *Bulkhead) Execute(ctx context.Context, operation func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if err := b.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer b.release()
+	return operation(ctx)
+}
+
+// Call runs an operation that does not take a context
+func (b *Bulkhead) Call(ctx context.Context, operation func() (interface{}, error)) (interface{}, error) {
+	return b.Execute(ctx, func(context.Context) (interface{}, error) {
+		return operation()
+	})
+}
+
+// GetMetrics returns the current metrics of the bulkhead
+func (b *Bulkhead) GetMetrics() BulkheadMetrics {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.metrics
+}
+
+func (b *Bulkhead) acquire(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		b.admitted()
+		return nil
+	default:
+	}
+
+	b.mutex.Lock()
+	if b.metrics.Waiting >= b.config.MaxWaiting {
+		b.metrics.Rejected++
+		b.mutex.Unlock()
+		return ErrBulkheadFull
+	}
+	b.metrics.Waiting++
+	b.mutex.Unlock()
+
+	var timeout <-chan time.Time
+	if b.config.MaxWait > 0 {
+		timer := time.NewTimer(b.config.MaxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		b.dequeue(false)
+		b.admitted()
+		return nil
+	case <-timeout:
+		b.dequeue(true)
+		return ErrBulkheadFull
+	case <-ctx.Done():
+		b.dequeue(false)
+		return ctx.Err()
+	}
+}
+
+func (b *Bulkhead) admitted() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.metrics.Active++
+	b.metrics.Admitted++
+}
+
+func (b *Bulkhead) dequeue(rejected bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.metrics.Waiting--
+	if rejected {
+		b.metrics.Rejected++
+	}
+}
+
+func (b *Bulkhead) release() {
+	b.mutex.Lock()
+	b.metrics.Active--
+	b.mutex.Unlock()
+	<-b.slots
+}
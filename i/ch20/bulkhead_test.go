@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingOperation runs until released, reporting each start on started
+type blockingOperation struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingOperation() *blockingOperation {
+	return &blockingOperation{started: make(chan struct{}, 100), release: make(chan struct{})}
+}
+
+func (b *blockingOperation) execute(context.Context) (interface{}, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return "success", nil
+}
+
+func TestBulkhead(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Rejects When Full", func(t *testing.T) {
+		b := NewBulkhead(BulkheadConfig{MaxConcurrent: 2})
+		op := newBlockingOperation()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				b.Execute(ctx, op.execute)
+			}()
+			<-op.started
+		}
+
+		if _, err := b.Execute(ctx, op.execute); !errors.Is(err, ErrBulkheadFull) {
+			t.Errorf("Expected ErrBulkheadFull, got %v", err)
+		}
+		if m := b.GetMetrics(); m.Active != 2 || m.Rejected != 1 {
+			t.Errorf("Expected 2 active and 1 rejected, got %+v", m)
+		}
+
+		close(op.release)
+		wg.Wait()
+		if m := b.GetMetrics(); m.Active != 0 || m.Admitted != 2 {
+			t.Errorf("Expected 0 active and 2 admitted, got %+v", m)
+		}
+	})
+
+	t.Run("Queues Until Slot Frees", func(t *testing.T) {
+		b := NewBulkhead(BulkheadConfig{MaxConcurrent: 1, MaxWaiting: 1, MaxWait: time.Second})
+		op := newBlockingOperation()
+
+		go b.Execute(ctx, op.execute)
+		<-op.started
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := b.Execute(ctx, func(context.Context) (interface{}, error) { return nil, nil })
+			done <- err
+		}()
+		waitFor(t, func() bool { return b.GetMetrics().Waiting == 1 })
+
+		if _, err := b.Execute(ctx, op.execute); !errors.Is(err, ErrBulkheadFull) {
+			t.Errorf("Expected ErrBulkheadFull with a full queue, got %v", err)
+		}
+
+		close(op.release)
+		if err := <-done; err != nil {
+			t.Errorf("Expected queued call to run, got %v", err)
+		}
+	})
+
+	t.Run("Queue Timeout", func(t *testing.T) {
+		b := NewBulkhead(BulkheadConfig{MaxConcurrent: 1, MaxWaiting: 1, MaxWait: 10 * time.Millisecond})
+		op := newBlockingOperation()
+		defer close(op.release)
+
+		go b.Execute(ctx, op.execute)
+		<-op.started
+
+		if _, err := b.Execute(ctx, op.execute); !errors.Is(err, ErrBulkheadFull) {
+			t.Errorf("Expected ErrBulkheadFull after MaxWait, got %v", err)
+		}
+		if m := b.GetMetrics(); m.Waiting != 0 || m.Rejected != 1 {
+			t.Errorf("Expected empty queue and 1 rejection, got %+v", m)
+		}
+	})
+
+	t.Run("Queue Context", func(t *testing.T) {
+		b := NewBulkhead(BulkheadConfig{MaxConcurrent: 1, MaxWaiting: 1})
+		op := newBlockingOperation()
+		defer close(op.release)
+
+		go b.Execute(ctx, op.execute)
+		<-op.started
+
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if _, err := b.Execute(waitCtx, op.execute); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+		if m := b.GetMetrics(); m.Waiting != 0 || m.Rejected != 0 {
+			t.Errorf("Expected empty queue and no rejections, got %+v", m)
+		}
+	})
+}
+
+func TestBulkheadConcurrencyLimit(t *testing.T) {
+	b := NewBulkhead(BulkheadConfig{MaxConcurrent: 3, MaxWaiting: 100})
+	var mu sync.Mutex
+	active, peak := 0, 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Execute(context.Background(), func(context.Context) (interface{}, error) {
+				mu.Lock()
+				active++
+				peak = max(peak, active)
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				active--
+				mu.Unlock()
+				return nil, nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	if peak > 3 {
+		t.Errorf("Expected at most 3 concurrent calls, got %d", peak)
+	}
+	if m := b.GetMetrics(); m.Admitted != 20 {
+		t.Errorf("Expected 20 admitted calls, got %d", m.Admitted)
+	}
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Order", func(t *testing.T) {
+		var order []string
+		tracer := func(name string) Policy {
+			return policyFunc(func(ctx context.Context, op func(context.Context) (interface{}, error)) (interface{}, error) {
+				order = append(order, name)
+				return op(ctx)
+			})
+		}
+
+		Chain(tracer("outer"), tracer("middle"), tracer("inner")).Execute(ctx, func(context.Context) (interface{}, error) {
+			order = append(order, "operation")
+			return nil, nil
+		})
+		want := []string{"outer", "middle", "inner", "operation"}
+		if len(order) != len(want) {
+			t.Fatalf("Expected %v, got %v", want, order)
+		}
+		for i := range want {
+			if order[i] != want[i] {
+				t.Fatalf("Expected %v, got %v", want, order)
+			}
+		}
+	})
+
+	t.Run("Bulkhead Retry Breaker", func(t *testing.T) {
+		cb := NewCircuitBreaker(Config{
+			ReadyToTrip: func(m Metrics) bool { return m.ConsecutiveFailures >= 3 },
+		})
+		policy := Chain(
+			NewBulkhead(BulkheadConfig{MaxConcurrent: 1}),
+			NewRetrier(RetryConfig{MaxAttempts: 5, Backoff: ConstantBackoff(0)}),
+			cb,
+		)
+
+		op := &flakyOperation{failures: 2, err: errors.New("transient")}
+		if result, err := policy.Execute(ctx, op.execute); err != nil || result != "success" {
+			t.Errorf("Expected success after retries, got %v, %v", result, err)
+		}
+
+		op = &flakyOperation{failures: 10, err: errors.New("down")}
+		if _, err := policy.Execute(ctx, op.execute); !errors.Is(err, ErrCircuitBreakerOpen) {
+			t.Errorf("Expected ErrCircuitBreakerOpen, got %v", err)
+		}
+		if op.calls != 3 {
+			t.Errorf("Expected retries to stop when the breaker opened, got %d calls", op.calls)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		result, err := Chain().Execute(ctx, func(context.Context) (interface{}, error) { return 1, nil })
+		if err != nil || result != 1 {
+			t.Errorf("Expected the bare operation result, got %v, %v", result, err)
+		}
+	})
+}
+
+// policyFunc adapts a function to the Policy interface
+type policyFunc func(ctx context.Context, operation func(context.Context) (interface{}, error)) (interface{}, error)
+
+func (f policyFunc) Execute(ctx context.Context, operation func(context.Context) (interface{}, error)) (interface{}, error) {
+	return f(ctx, operation)
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
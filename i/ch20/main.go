@@ -16,6 +16,11 @@ const (
 	StateClosed State = iota
 	StateOpen
 	StateHalfOpen
+
+	// Manual override states, left only by another manual operation
+	StateForcedOpen   // Rejects every call
+	StateForcedClosed // Admits every call and records metrics, but never trips
+	StateDisabled     // Admits every call without recording anything
 )
 
 // String returns the string representation of the state
@@ -27,6 +32,12 @@ func (s State) String() string {
 		return "Open"
 	case StateHalfOpen:
 		return "Half-Open"
+	case StateForcedOpen:
+		return "Forced-Open"
+	case StateForcedClosed:
+		return "Forced-Closed"
+	case StateDisabled:
+		return "Disabled"
 	default:
 		return "Unknown"
 	}
@@ -38,23 +49,59 @@ type Metrics struct {
 	Successes           int64
 	Failures            int64
 	ConsecutiveFailures int64
+	SlowCalls           int64         // Calls that took at least SlowCallDurationThreshold
+	Cancellations       int64         // Calls cancelled by the caller's context, not counted in Requests
+	Ignored             int64         // Calls whose error matched Config.IsIgnored, not counted in Requests
+	OpenDuration        time.Duration // How long the current open period lasts, 0 unless Open
+	Fallbacks           int64         // Calls answered by Config.Fallback
+	StoreErrors         int64         // Failed Config.StateStore reads and writes
 	LastFailureTime     time.Time
+	Window              WindowMetrics // Calls inside the rolling window
 }
 
 // Config represents the configuration for the circuit breaker
 type Config struct {
-	MaxRequests   uint32                                  // Max requests allowed in half-open state
+	Name          string                                  // Name passed to OnStateChange, "circuit-breaker" by default
+	MaxRequests   uint32                                  // Max concurrent requests allowed in half-open state
 	Interval      time.Duration                           // Statistical window for closed state
 	Timeout       time.Duration                           // Time to wait before half-open
+	OpenBackoff   Backoff                                 // Grows the wait over consecutive open periods; Timeout when nil
 	ReadyToTrip   func(Metrics) bool                      // Function to determine when to trip
 	OnStateChange func(name string, from State, to State) // State change callback
+
+	WindowType    WindowType // Rolling window kind, time-based by default
+	WindowSize    int        // Calls kept by a CountWindow
+	WindowBuckets int        // Buckets Interval is split into by a TimeWindow
+
+	SlowCallDurationThreshold time.Duration // Calls taking at least this long count as slow, tripping only through ReadyToTrip; 0 disables
+	CallTimeout               time.Duration // Per-call deadline, exceeding it fails with ErrCallTimeout; 0 disables
+
+	IsSuccessful func(err error) bool // Reports whether a call counts as a success; defaults to err == nil
+	IsIgnored    func(err error) bool // Reports whether a call counts as neither success nor failure
+
+	SuccessThreshold uint32        // Consecutive half-open successes needed to close, 1 by default
+	RampUpDuration   time.Duration // After recovering, admit a share of traffic growing to 100% over this period; 0 disables
+
+	Clock Clock // Time source for state transitions, windows and call durations; the system clock by default
+
+	Fallback          func(ctx context.Context, err error) (interface{}, error) // Answers calls the breaker rejects
+	FallbackOnFailure bool                                                      // Also answer calls that fail, using their error
+
+	StateStore        StateStore    // Shares trips and recoveries with other replicas; nil keeps state local
+	StateSyncInterval time.Duration // Minimum time between StateStore reads, 1s by default
 }
 
 // CircuitBreaker interface defines the operations for a circuit breaker
 type CircuitBreaker interface {
 	Call(ctx context.Context, operation func() (interface{}, error)) (interface{}, error)
+	Execute(ctx context.Context, operation func(ctx context.Context) (interface{}, error)) (interface{}, error)
 	GetState() State
 	GetMetrics() Metrics
+
+	ForceOpen()   // Reject all calls until another manual operation
+	ForceClosed() // Admit all calls and never trip until another manual operation
+	Disable()     // Bypass the breaker entirely until another manual operation
+	Reset()       // Return to Closed with cleared metrics
 }
 
 // circuitBreakerImpl is the concrete implementation of CircuitBreaker
@@ -63,28 +110,71 @@ type circuitBreakerImpl struct {
 	config           Config
 	state            State
 	metrics          Metrics
+	window           rollingWindow
 	lastStateChange  time.Time
-	halfOpenRequests uint32
+	openDuration     time.Duration // length of the current open period
+	openPeriods      int           // open periods since the breaker last closed
+	generation       uint64        // incremented on every state change
+	halfOpenRequests uint32        // half-open calls in flight
+	halfOpenSuccess  uint32        // consecutive successful half-open calls
+	rampStart        time.Time
+	rampCredit       float64      // admissions owed during the ramp-up
+	lastSync         time.Time    // last StateStore read
+	pending          *SharedState // transition waiting to be published
+	adopting         bool         // applying a transition read from the StateStore
 	mutex            sync.Mutex
 }
 
+// minRampUpShare is the share of traffic admitted at the start of a ramp-up
+const minRampUpShare = 0.1
+
 // Error definitions
 var (
 	ErrCircuitBreakerOpen = errors.New("circuit breaker is open")
 	ErrTooManyRequests    = errors.New("too many requests in half-open state")
+	ErrCallTimeout        = errors.New("circuit breaker call timed out")
+	ErrRampingUp          = errors.New("request rejected while ramping up after recovery")
 )
 
 // NewCircuitBreaker creates a new circuit breaker with the given configuration
 func NewCircuitBreaker(config Config) CircuitBreaker {
+	return newCircuitBreaker(config)
+}
+
+func newCircuitBreaker(config Config) *circuitBreakerImpl {
+	if config.Name == "" {
+		config.Name = "circuit-breaker"
+	}
 	if config.MaxRequests == 0 {
 		config.MaxRequests = 1
 	}
+	if config.SuccessThreshold == 0 {
+		config.SuccessThreshold = 1
+	}
 	if config.Interval == 0 {
 		config.Interval = time.Minute
 	}
 	if config.Timeout == 0 {
 		config.Timeout = 30 * time.Second
 	}
+	if config.WindowSize <= 0 {
+		config.WindowSize = 100
+	}
+	if config.WindowBuckets <= 0 {
+		config.WindowBuckets = 10
+	}
+	if config.Interval < time.Duration(config.WindowBuckets) {
+		config.Interval = time.Duration(config.WindowBuckets)
+	}
+	if config.Clock == nil {
+		config.Clock = realClock{}
+	}
+	if config.StateSyncInterval <= 0 {
+		config.StateSyncInterval = time.Second
+	}
+	if config.IsSuccessful == nil {
+		config.IsSuccessful = func(err error) bool { return err == nil }
+	}
 	if config.ReadyToTrip == nil {
 		config.ReadyToTrip = func(m Metrics) bool {
 			return m.ConsecutiveFailures >= 5
@@ -92,25 +182,53 @@ func NewCircuitBreaker(config Config) CircuitBreaker {
 	}
 
 	return &circuitBreakerImpl{
-		name:            "circuit-breaker",
+		name:            config.Name,
 		config:          config,
 		state:           StateClosed,
-		lastStateChange: time.Now(),
+		window:          newRollingWindow(config),
+		lastStateChange: config.Clock.Now(),
 	}
 }
 
 // Call executes the given operation through the circuit breaker
 func (cb *circuitBreakerImpl) Call(ctx context.Context, operation func() (interface{}, error)) (interface{}, error) {
+	return cb.Execute(ctx, func(context.Context) (interface{}, error) {
+		return operation()
+	})
+}
+
+// Execute runs a context-aware operation through the circuit breaker. The
+// operation receives ctx, bounded by Config.CallTimeout when one is set. If
+// the operation fails because the caller cancelled ctx, the call counts as a
+// cancellation rather than a failure and never trips the breaker. A caller
+// deadline that expires is still a failure, as it is how a hung dependency
+// shows up.
+// Rejected calls, and failed ones when FallbackOnFailure is set, are answered
+// by Config.Fallback if there is one.
+func (cb *circuitBreakerImpl) Execute(ctx context.Context, operation func(ctx context.Context) (interface{}, error)) (interface{}, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	if err := cb.beforeCall(); err != nil {
-		return nil, err
+	generation, err := cb.beforeCall()
+	if err != nil {
+		return cb.fallback(ctx, err)
 	}
 
-	result, err := operation()
-	cb.afterCall(err)
+	start := cb.config.Clock.Now()
+	result, err := cb.run(ctx, operation)
+	switch {
+	case err != nil && isCallerCancellation(ctx, err):
+		cb.afterSkipped(generation, &cb.metrics.Cancellations)
+	case cb.config.IsIgnored != nil && cb.config.IsIgnored(err):
+		cb.afterSkipped(generation, &cb.metrics.Ignored)
+	default:
+		failure := !cb.config.IsSuccessful(err)
+		cb.afterCall(generation, failure, cb.config.Clock.Now().Sub(start))
+		if failure && cb.config.FallbackOnFailure {
+			return cb.fallback(ctx, err)
+		}
+	}
 
 	if err != nil {
 		return nil, err
@@ -118,43 +236,172 @@ func (cb *circuitBreakerImpl) Call(ctx context.Context, operation func() (interf
 	return result, nil
 }
 
+// fallback answers a rejected or failed call with Config.Fallback, or
+// returns err unchanged when no fallback is configured.
+func (cb *circuitBreakerImpl) fallback(ctx context.Context, err error) (interface{}, error) {
+	if cb.config.Fallback == nil {
+		return nil, err
+	}
+
+	cb.mutex.Lock()
+	cb.metrics.Fallbacks++
+	cb.mutex.Unlock()
+
+	return cb.config.Fallback(ctx, err)
+}
+
+// IgnoreErrors returns an IsIgnored function matching any of errs, or any
+// error wrapping one of them.
+func IgnoreErrors(errs ...error) func(error) bool {
+	return func(err error) bool {
+		if err == nil {
+			return false
+		}
+		for _, target := range errs {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// isCallerCancellation reports whether err stems from the caller cancelling
+// its own context rather than from the dependency. Expired deadlines do not
+// count.
+func isCallerCancellation(ctx context.Context, err error) bool {
+	return errors.Is(ctx.Err(), context.Canceled) && errors.Is(err, context.Canceled)
+}
+
+// callResult carries the outcome of an operation run on its own goroutine
+type callResult struct {
+	value interface{}
+	err   error
+}
+
+// run invokes operation, bounding it by Config.CallTimeout when one is set.
+// A timed-out operation keeps running in the background with a cancelled
+// context; its eventual result is discarded. The deadline follows the system
+// clock rather than Config.Clock, as it is enforced through the context.
+func (cb *circuitBreakerImpl) run(ctx context.Context, operation func(context.Context) (interface{}, error)) (interface{}, error) {
+	if cb.config.CallTimeout <= 0 {
+		return operation(ctx)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, cb.config.CallTimeout)
+	defer cancel()
+
+	done := make(chan callResult, 1)
+	go func() {
+		value, err := operation(callCtx)
+		done <- callResult{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil && errors.Is(r.err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, ErrCallTimeout
+		}
+		return r.value, r.err
+	case <-callCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrCallTimeout
+	}
+}
+
 // beforeCall validates the breaker permits execution and performs any eager
-// state transition (e.g. Open -> HalfOpen once Timeout has elapsed).
-func (cb *circuitBreakerImpl) beforeCall() error {
+// state transition (e.g. Open -> HalfOpen once Timeout has elapsed). It
+// returns the state generation the call was admitted in.
+func (cb *circuitBreakerImpl) beforeCall() (uint64, error) {
+	cb.syncState()
+
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
+	if cb.state == StateForcedOpen {
+		return 0, ErrCircuitBreakerOpen
+	}
+
 	if cb.state == StateOpen {
-		if time.Since(cb.lastStateChange) < cb.config.Timeout {
-			return ErrCircuitBreakerOpen
+		if cb.config.Clock.Now().Sub(cb.lastStateChange) < cb.openDuration {
+			return 0, ErrCircuitBreakerOpen
 		}
 		cb.setStateLocked(StateHalfOpen)
 	}
 
 	if cb.state == StateHalfOpen {
 		if cb.halfOpenRequests >= cb.config.MaxRequests {
-			return ErrTooManyRequests
+			return 0, ErrTooManyRequests
 		}
 		cb.halfOpenRequests++
 	}
 
-	return nil
+	if cb.state == StateClosed && !cb.rampStart.IsZero() && !cb.admitRampUpLocked() {
+		return 0, ErrRampingUp
+	}
+
+	return cb.generation, nil
+}
+
+// admitRampUpLocked spreads admissions evenly so that the admitted share of
+// calls tracks the ramp-up progress, starting at minRampUpShare. Each call
+// earns credit equal to the current share and one credit admits one call.
+// Must be called with cb.mutex held.
+func (cb *circuitBreakerImpl) admitRampUpLocked() bool {
+	elapsed := cb.config.Clock.Now().Sub(cb.rampStart)
+	if elapsed >= cb.config.RampUpDuration {
+		cb.rampStart = time.Time{}
+		return true
+	}
+
+	cb.rampCredit += max(minRampUpShare, float64(elapsed)/float64(cb.config.RampUpDuration))
+	if cb.rampCredit < 1 {
+		return false
+	}
+	cb.rampCredit--
+	return true
+}
+
+// releaseLocked frees the half-open slot held by a call admitted in
+// generation, unless the breaker has changed state since.
+// Must be called with cb.mutex held.
+func (cb *circuitBreakerImpl) releaseLocked(generation uint64) {
+	if cb.state == StateHalfOpen && generation == cb.generation && cb.halfOpenRequests > 0 {
+		cb.halfOpenRequests--
+	}
 }
 
 // afterCall records the operation outcome and drives state transitions.
-func (cb *circuitBreakerImpl) afterCall(err error) {
+// Outcomes of calls admitted before the latest state change are discarded,
+// so a slow call from the closed state cannot close a half-open breaker
+// while its probe is still in flight.
+func (cb *circuitBreakerImpl) afterCall(generation uint64, failure bool, duration time.Duration) {
+	defer cb.publishPending()
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
+	if cb.state == StateDisabled || generation != cb.generation {
+		return
+	}
+	cb.releaseLocked(generation)
+
+	now := cb.config.Clock.Now()
+	slow := cb.config.SlowCallDurationThreshold > 0 && duration >= cb.config.SlowCallDurationThreshold
 	cb.metrics.Requests++
-	if err != nil {
+	if slow {
+		cb.metrics.SlowCalls++
+	}
+	cb.window.record(now, outcome{failure: failure, slow: slow})
+	if failure {
 		cb.metrics.Failures++
 		cb.metrics.ConsecutiveFailures++
-		cb.metrics.LastFailureTime = time.Now()
+		cb.metrics.LastFailureTime = now
 
 		switch cb.state {
 		case StateClosed:
-			if cb.config.ReadyToTrip(cb.metrics) {
+			if cb.config.ReadyToTrip(cb.metricsLocked(now)) {
 				cb.setStateLocked(StateOpen)
 			}
 		case StateHalfOpen:
@@ -165,11 +412,42 @@ func (cb *circuitBreakerImpl) afterCall(err error) {
 
 	cb.metrics.Successes++
 	cb.metrics.ConsecutiveFailures = 0
-	if cb.state == StateHalfOpen {
-		cb.setStateLocked(StateClosed)
+	switch cb.state {
+	case StateClosed:
+		// A slow success can still trip the breaker on a slow-call-rate condition
+		if slow && cb.config.ReadyToTrip(cb.metricsLocked(now)) {
+			cb.setStateLocked(StateOpen)
+		}
+	case StateHalfOpen:
+		// A slow probe reopens the breaker only on the same condition
+		if slow && cb.config.ReadyToTrip(cb.metricsLocked(now)) {
+			cb.setStateLocked(StateOpen)
+			return
+		}
+		cb.halfOpenSuccess++
+		if cb.halfOpenSuccess >= cb.config.SuccessThreshold {
+			cb.setStateLocked(StateClosed)
+			if cb.config.RampUpDuration > 0 {
+				cb.rampStart = now
+			}
+		}
 	}
 }
 
+// afterSkipped records a call that counts as neither success nor failure,
+// incrementing counter. It frees the half-open slot the call held so the
+// probe can be retried.
+func (cb *circuitBreakerImpl) afterSkipped(generation uint64, counter *int64) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.state == StateDisabled {
+		return
+	}
+	*counter++
+	cb.releaseLocked(generation)
+}
+
 // GetState returns the current state of the circuit breaker
 func (cb *circuitBreakerImpl) GetState() State {
 	cb.mutex.Lock()
@@ -181,7 +459,53 @@ func (cb *circuitBreakerImpl) GetState() State {
 func (cb *circuitBreakerImpl) GetMetrics() Metrics {
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
-	return cb.metrics
+	return cb.metricsLocked(cb.config.Clock.Now())
+}
+
+// metricsLocked returns the counters with a fresh rolling window snapshot.
+// Must be called with cb.mutex held.
+func (cb *circuitBreakerImpl) metricsLocked(now time.Time) Metrics {
+	m := cb.metrics
+	m.Window = cb.window.snapshot(now)
+	if cb.state == StateOpen {
+		m.OpenDuration = cb.openDuration
+	}
+	return m
+}
+
+// ForceOpen rejects every call with ErrCircuitBreakerOpen until another
+// manual operation; Timeout no longer moves the breaker to half-open.
+func (cb *circuitBreakerImpl) ForceOpen() { cb.transition(StateForcedOpen) }
+
+// ForceClosed admits every call until another manual operation. Metrics keep
+// being recorded but ReadyToTrip is never consulted.
+func (cb *circuitBreakerImpl) ForceClosed() { cb.transition(StateForcedClosed) }
+
+// Disable turns the breaker into a pass-through until another manual
+// operation. Calls are neither limited nor recorded.
+func (cb *circuitBreakerImpl) Disable() { cb.transition(StateDisabled) }
+
+// Reset returns the breaker to Closed and clears its metrics, including when
+// it is already closed.
+func (cb *circuitBreakerImpl) Reset() {
+	defer cb.publishPending()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.state == StateClosed {
+		cb.metrics = Metrics{}
+		cb.window.reset()
+		return
+	}
+	cb.setStateLocked(StateClosed)
+}
+
+// transition forces the breaker into the given state
+func (cb *circuitBreakerImpl) transition(to State) {
+	defer cb.publishPending()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	cb.setStateLocked(to)
 }
 
 // setStateLocked transitions state and resets window-scoped counters.
@@ -193,12 +517,26 @@ func (cb *circuitBreakerImpl) setStateLocked(newState State) {
 
 	oldState := cb.state
 	cb.state = newState
-	cb.lastStateChange = time.Now()
+	cb.lastStateChange = cb.config.Clock.Now()
+	cb.generation++
 	cb.halfOpenRequests = 0
+	cb.halfOpenSuccess = 0
+	cb.rampStart = time.Time{}
+	cb.rampCredit = 1 // admit the first call straight away
 
-	if newState == StateClosed {
+	switch newState {
+	case StateOpen:
+		cb.openDuration = cb.config.Timeout
+		if cb.config.OpenBackoff != nil {
+			cb.openDuration = cb.config.OpenBackoff.Duration(cb.openPeriods)
+		}
+		cb.openPeriods++
+	case StateClosed:
 		cb.metrics = Metrics{}
+		cb.window.reset()
+		cb.openPeriods = 0
 	}
+	cb.queuePublishLocked(newState)
 
 	if cb.config.OnStateChange != nil {
 		cb.config.OnStateChange(cb.name, oldState, newState)
@@ -236,4 +574,4 @@ func main() {
 
 	fmt.Printf("Current state: %v\n", cb.GetState())
 	fmt.Printf("Current metrics: %+v\n", cb.GetMetrics())
-}
\ No newline at end of file
+}
@@ -14,6 +14,7 @@ type mockOperation struct {
 	shouldFail bool
 	delay      time.Duration
 	callCount  int
+	clock      *FakeClock // when set, delay advances the clock instead of sleeping
 	mutex      sync.Mutex
 }
 
@@ -22,7 +23,9 @@ func (m *mockOperation) execute() (interface{}, error) {
 	m.callCount++
 	m.mutex.Unlock()
 
-	if m.delay > 0 {
+	if m.delay > 0 && m.clock != nil {
+		m.clock.Advance(m.delay)
+	} else if m.delay > 0 {
 		time.Sleep(m.delay)
 	}
 
@@ -193,9 +196,11 @@ func TestFastFailWhenOpen(t *testing.T) {
 }
 
 func TestHalfOpenTransition(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
 	config := Config{
 		MaxRequests: 2,
 		Timeout:     50 * time.Millisecond,
+		Clock:       clock,
 		ReadyToTrip: func(m Metrics) bool {
 			return m.ConsecutiveFailures >= 2
 		},
@@ -216,7 +221,7 @@ func TestHalfOpenTransition(t *testing.T) {
 	}
 
 	// Wait for timeout to elapse
-	time.Sleep(60 * time.Millisecond)
+	clock.Advance(60 * time.Millisecond)
 
 	// Next call should transition to half-open
 	op.shouldFail = false // Make operation succeed
@@ -237,9 +242,11 @@ func TestHalfOpenTransition(t *testing.T) {
 }
 
 func TestHalfOpenToClosedTransition(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
 	config := Config{
 		MaxRequests: 2,
 		Timeout:     50 * time.Millisecond,
+		Clock:       clock,
 		ReadyToTrip: func(m Metrics) bool {
 			return m.ConsecutiveFailures >= 2
 		},
@@ -255,7 +262,7 @@ func TestHalfOpenToClosedTransition(t *testing.T) {
 	}
 
 	// Wait for timeout
-	time.Sleep(60 * time.Millisecond)
+	clock.Advance(60 * time.Millisecond)
 
 	// Make operation succeed to close the circuit
 	op.shouldFail = false
@@ -268,9 +275,11 @@ func TestHalfOpenToClosedTransition(t *testing.T) {
 }
 
 func TestHalfOpenToOpenTransition(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
 	config := Config{
 		MaxRequests: 2,
 		Timeout:     50 * time.Millisecond,
+		Clock:       clock,
 		ReadyToTrip: func(m Metrics) bool {
 			return m.ConsecutiveFailures >= 2
 		},
@@ -286,7 +295,7 @@ func TestHalfOpenToOpenTransition(t *testing.T) {
 	}
 
 	// Wait for timeout
-	time.Sleep(60 * time.Millisecond)
+	clock.Advance(60 * time.Millisecond)
 
 	// Keep operation failing - should go back to open
 	cb.Call(ctx, op.execute)
@@ -298,9 +307,11 @@ func TestHalfOpenToOpenTransition(t *testing.T) {
 }
 
 func TestMaxRequestsInHalfOpen(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
 	config := Config{
 		MaxRequests: 2,
 		Timeout:     50 * time.Millisecond,
+		Clock:       clock,
 		ReadyToTrip: func(m Metrics) bool {
 			return m.ConsecutiveFailures >= 2
 		},
@@ -308,7 +319,7 @@ func TestMaxRequestsInHalfOpen(t *testing.T) {
 
 	cb := NewCircuitBreaker(config)
 	ctx := context.Background()
-	op := &mockOperation{shouldFail: true, delay: 100 * time.Millisecond}
+	op := &mockOperation{shouldFail: true}
 
 	// Trip the circuit
 	for i := 0; i < 2; i++ {
@@ -316,21 +327,29 @@ func TestMaxRequestsInHalfOpen(t *testing.T) {
 	}
 
 	// Wait for timeout
-	time.Sleep(60 * time.Millisecond)
+	clock.Advance(60 * time.Millisecond)
+
+	// Hold MaxRequests probes in flight, then issue more requests in half-open state
+	probe := newBlockingOperation()
+	execute := func() (interface{}, error) { return probe.execute(ctx) }
 
-	// Start multiple concurrent requests in half-open state
 	var wg sync.WaitGroup
 	results := make([]error, 5)
 
-	for i := 0; i < 5; i++ {
+	for i := 0; i < 2; i++ {
 		wg.Add(1)
 		go func(index int) {
 			defer wg.Done()
-			_, err := cb.Call(ctx, op.execute)
+			_, err := cb.Call(ctx, execute)
 			results[index] = err
 		}(i)
+		<-probe.started
+	}
+	for i := 2; i < 5; i++ {
+		_, results[i] = cb.Call(ctx, execute)
 	}
 
+	close(probe.release)
 	wg.Wait()
 
 	// Some requests should be rejected due to MaxRequests limit
@@ -341,8 +360,8 @@ func TestMaxRequestsInHalfOpen(t *testing.T) {
 		}
 	}
 
-	if rejectedCount == 0 {
-		t.Error("Expected some requests to be rejected due to MaxRequests limit in half-open state")
+	if rejectedCount != 3 {
+		t.Errorf("Expected 3 requests to be rejected due to MaxRequests limit in half-open state, got %d", rejectedCount)
 	}
 }
 
@@ -385,9 +404,11 @@ func TestConcurrentAccess(t *testing.T) {
 
 func TestStateChangeCallback(t *testing.T) {
 	var stateChanges []string
+	clock := NewFakeClock(time.Unix(0, 0))
 	config := Config{
 		MaxRequests: 2,
 		Timeout:     50 * time.Millisecond,
+		Clock:       clock,
 		ReadyToTrip: func(m Metrics) bool {
 			return m.ConsecutiveFailures >= 2
 		},
@@ -406,7 +427,7 @@ func TestStateChangeCallback(t *testing.T) {
 	}
 
 	// Wait for timeout and make successful call (should trigger Open->HalfOpen->Closed)
-	time.Sleep(60 * time.Millisecond)
+	clock.Advance(60 * time.Millisecond)
 	op.shouldFail = false
 	cb.Call(ctx, op.execute)
 
@@ -477,3 +498,1016 @@ func TestMetricsAccuracy(t *testing.T) {
 		t.Errorf("Expected 3 consecutive failures, got %d", metrics.ConsecutiveFailures)
 	}
 }
+
+func TestCountWindow(t *testing.T) {
+	config := Config{
+		WindowType: CountWindow,
+		WindowSize: 4,
+		ReadyToTrip: func(m Metrics) bool {
+			return false
+		},
+	}
+
+	cb := NewCircuitBreaker(config)
+	ctx := context.Background()
+	failOp := &mockOperation{shouldFail: true}
+	successOp := &mockOperation{shouldFail: false}
+
+	for i := 0; i < 3; i++ {
+		cb.Call(ctx, failOp.execute)
+	}
+	for i := 0; i < 3; i++ {
+		cb.Call(ctx, successOp.execute)
+	}
+
+	// Only the last 4 calls (1 failure, 3 successes) remain in the window,
+	// while the cumulative counters keep everything.
+	metrics := cb.GetMetrics()
+	if metrics.Window.Requests != 4 || metrics.Window.Failures != 1 {
+		t.Errorf("Expected window of 4 requests with 1 failure, got %+v", metrics.Window)
+	}
+	if metrics.Window.FailureRate != 0.25 {
+		t.Errorf("Expected window failure rate 0.25, got %f", metrics.Window.FailureRate)
+	}
+	if metrics.Requests != 6 || metrics.Failures != 3 {
+		t.Errorf("Expected cumulative 6 requests with 3 failures, got %+v", metrics)
+	}
+}
+
+func TestTimeWindow(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	config := Config{
+		Interval:      100 * time.Millisecond,
+		WindowBuckets: 5,
+		Clock:         clock,
+		ReadyToTrip: func(m Metrics) bool {
+			return false
+		},
+	}
+
+	cb := NewCircuitBreaker(config)
+	ctx := context.Background()
+	failOp := &mockOperation{shouldFail: true}
+
+	cb.Call(ctx, failOp.execute)
+	clock.Advance(60 * time.Millisecond)
+	cb.Call(ctx, failOp.execute)
+	if w := cb.GetMetrics().Window; w.Requests != 2 || w.Failures != 2 {
+		t.Errorf("Expected 2 failures in window, got %+v", w)
+	}
+
+	// Buckets older than the interval no longer count.
+	clock.Advance(50 * time.Millisecond)
+	if w := cb.GetMetrics().Window; w.Requests != 1 {
+		t.Errorf("Expected the first failure to have expired, got %+v", w)
+	}
+	clock.Advance(60 * time.Millisecond)
+	if w := cb.GetMetrics().Window; w.Requests != 0 {
+		t.Errorf("Expected empty window after interval, got %+v", w)
+	}
+	if m := cb.GetMetrics(); m.Failures != 2 {
+		t.Errorf("Expected cumulative failures to remain 2, got %d", m.Failures)
+	}
+}
+
+func TestTimeWindowEarlyClock(t *testing.T) {
+	starts := map[string]time.Time{
+		"Zero Time":   {},
+		"Before 1970": time.Date(1960, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for name, start := range starts {
+		t.Run(name, func(t *testing.T) {
+			clock := NewFakeClock(start)
+			cb := NewCircuitBreaker(Config{
+				Clock:         clock,
+				Interval:      100 * time.Millisecond,
+				WindowBuckets: 5,
+				ReadyToTrip: func(m Metrics) bool {
+					return false
+				},
+			})
+			ctx := context.Background()
+			failOp := &mockOperation{shouldFail: true}
+
+			for i := 0; i < 7; i++ {
+				cb.Call(ctx, failOp.execute)
+				clock.Advance(30 * time.Millisecond)
+			}
+			// Calls at 120ms, 150ms and 180ms fall in the 100ms window ending at 210ms.
+			if w := cb.GetMetrics().Window; w.Requests != 3 {
+				t.Errorf("Expected the last 3 calls in the window, got %+v", w)
+			}
+
+			// A clock moved back before the breaker was created must not panic.
+			clock.Set(start.Add(-time.Hour))
+			cb.Call(ctx, failOp.execute)
+			if w := cb.GetMetrics().Window; w.Requests != 1 {
+				t.Errorf("Expected only the latest call in the window, got %+v", w)
+			}
+		})
+	}
+}
+
+func TestTripOnFailureRate(t *testing.T) {
+	config := Config{
+		WindowType:  CountWindow,
+		WindowSize:  10,
+		ReadyToTrip: TripOnFailureRate(50, 4),
+	}
+
+	cb := NewCircuitBreaker(config)
+	ctx := context.Background()
+	failOp := &mockOperation{shouldFail: true}
+	successOp := &mockOperation{shouldFail: false}
+
+	// Below the minimum volume the breaker stays closed even at 100% failures.
+	for i := 0; i < 3; i++ {
+		cb.Call(ctx, failOp.execute)
+	}
+	if cb.GetState() != StateClosed {
+		t.Fatalf("Expected Closed below minimum requests, got %v", cb.GetState())
+	}
+
+	// ReadyToTrip is only consulted after a failure or slow call, so fast
+	// successes never trip.
+	for i := 0; i < 3; i++ {
+		cb.Call(ctx, successOp.execute)
+	}
+	if cb.GetState() != StateClosed {
+		t.Fatalf("Expected Closed after successes, got %v", cb.GetState())
+	}
+
+	cb.Call(ctx, failOp.execute) // 4/7 ≈ 57%
+	if cb.GetState() != StateOpen {
+		t.Errorf("Expected Open at 57%% failure rate, got %v", cb.GetState())
+	}
+}
+
+func TestWindowResetOnClose(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	config := Config{
+		Clock:       clock,
+		Timeout:     50 * time.Millisecond,
+		WindowType:  CountWindow,
+		ReadyToTrip: TripOnFailureRate(50, 2),
+	}
+
+	cb := NewCircuitBreaker(config)
+	ctx := context.Background()
+	op := &mockOperation{shouldFail: true}
+
+	cb.Call(ctx, op.execute)
+	cb.Call(ctx, op.execute)
+	if cb.GetState() != StateOpen {
+		t.Fatalf("Expected Open, got %v", cb.GetState())
+	}
+
+	clock.Advance(60 * time.Millisecond)
+	op.shouldFail = false
+	cb.Call(ctx, op.execute)
+	if cb.GetState() != StateClosed {
+		t.Fatalf("Expected Closed after successful probe, got %v", cb.GetState())
+	}
+	if w := cb.GetMetrics().Window; w.Requests != 0 {
+		t.Errorf("Expected empty window after closing, got %+v", w)
+	}
+}
+
+func TestSlowCallDetection(t *testing.T) {
+	for _, windowType := range []WindowType{CountWindow, TimeWindow} {
+		t.Run(windowType.String(), func(t *testing.T) {
+			clock := NewFakeClock(time.Unix(0, 0))
+			config := Config{
+				Clock:                     clock,
+				WindowType:                windowType,
+				WindowSize:                10,
+				Interval:                  time.Minute,
+				SlowCallDurationThreshold: 20 * time.Millisecond,
+				ReadyToTrip:               TripOnSlowCallRate(50, 4),
+			}
+
+			cb := NewCircuitBreaker(config)
+			ctx := context.Background()
+			fastOp := &mockOperation{}
+			slowOp := &mockOperation{delay: 30 * time.Millisecond, clock: clock}
+
+			cb.Call(ctx, fastOp.execute)
+			cb.Call(ctx, fastOp.execute)
+			cb.Call(ctx, slowOp.execute)
+			if cb.GetState() != StateClosed {
+				t.Fatalf("Expected Closed below minimum requests, got %v", cb.GetState())
+			}
+
+			m := cb.GetMetrics()
+			if m.SlowCalls != 1 || m.Window.SlowCalls != 1 {
+				t.Errorf("Expected 1 slow call, got %d (window %d)", m.SlowCalls, m.Window.SlowCalls)
+			}
+			if m.Window.SlowCallRate != 1.0/3 {
+				t.Errorf("Expected window slow call rate 1/3, got %f", m.Window.SlowCallRate)
+			}
+			if m.Successes != 3 || m.Failures != 0 {
+				t.Errorf("Expected slow calls to count as successes, got %+v", m)
+			}
+
+			cb.Call(ctx, slowOp.execute) // 2/4 = 50%
+			if cb.GetState() != StateOpen {
+				t.Errorf("Expected Open at 50%% slow call rate, got %v", cb.GetState())
+			}
+		})
+	}
+}
+
+func TestSlowCallInHalfOpen(t *testing.T) {
+	onFailures := func(m Metrics) bool { return m.ConsecutiveFailures >= 1 }
+	tests := []struct {
+		name        string
+		readyToTrip func(Metrics) bool
+		want        State
+	}{
+		// Slow calls only trip through ReadyToTrip, in half-open as in closed
+		{"Failure Condition", onFailures, StateClosed},
+		{"Slow Call Condition", TripOnAny(onFailures, TripOnSlowCallRate(50, 1)), StateOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewFakeClock(time.Unix(0, 0))
+			cb := NewCircuitBreaker(Config{
+				Clock:                     clock,
+				Timeout:                   50 * time.Millisecond,
+				SlowCallDurationThreshold: 20 * time.Millisecond,
+				ReadyToTrip:               tt.readyToTrip,
+			})
+			ctx := context.Background()
+			cb.Call(ctx, (&mockOperation{shouldFail: true}).execute)
+			clock.Advance(60 * time.Millisecond)
+
+			cb.Call(ctx, (&mockOperation{delay: 30 * time.Millisecond, clock: clock}).execute)
+			if cb.GetState() != tt.want {
+				t.Errorf("Expected %v after a slow successful probe, got %v", tt.want, cb.GetState())
+			}
+			if m := cb.GetMetrics(); tt.want == StateOpen && m.SlowCalls != 1 {
+				t.Errorf("Expected the probe to count as slow, got %d slow calls", m.SlowCalls)
+			}
+		})
+	}
+}
+
+func TestTripOnAny(t *testing.T) {
+	trip := TripOnAny(TripOnFailureRate(50, 2), TripOnSlowCallRate(50, 2))
+
+	tests := []struct {
+		name    string
+		metrics Metrics
+		want    bool
+	}{
+		{"Healthy", Metrics{Window: WindowMetrics{Requests: 4}}, false},
+		{"Failing", Metrics{Window: WindowMetrics{Requests: 4, FailureRate: 0.5}}, true},
+		{"Slow", Metrics{Window: WindowMetrics{Requests: 4, SlowCallRate: 0.75}}, true},
+		{"Low Volume", Metrics{Window: WindowMetrics{Requests: 1, FailureRate: 1}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trip(tt.metrics); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+	if TripOnAny()(Metrics{}) {
+		t.Error("Expected no conditions to never trip")
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	config := Config{
+		CallTimeout: 20 * time.Millisecond,
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 2
+		},
+	}
+
+	cb := NewCircuitBreaker(config)
+	ctx := context.Background()
+	slowOp := &mockOperation{delay: 200 * time.Millisecond}
+
+	start := time.Now()
+	_, err := cb.Call(ctx, slowOp.execute)
+	if !errors.Is(err, ErrCallTimeout) {
+		t.Fatalf("Expected ErrCallTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Expected Call to return at the timeout, took %v", elapsed)
+	}
+	if m := cb.GetMetrics(); m.Failures != 1 {
+		t.Errorf("Expected timeout to count as a failure, got %d failures", m.Failures)
+	}
+
+	result, err := cb.Call(ctx, (&mockOperation{}).execute)
+	if err != nil || result != "success" {
+		t.Errorf("Expected fast call to succeed, got %v, %v", result, err)
+	}
+
+	cb.Call(ctx, slowOp.execute)
+	cb.Call(ctx, slowOp.execute)
+	if cb.GetState() != StateOpen {
+		t.Errorf("Expected Open after repeated timeouts, got %v", cb.GetState())
+	}
+}
+
+func TestCallTimeoutParentCancelled(t *testing.T) {
+	cb := NewCircuitBreaker(Config{CallTimeout: time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := cb.Call(ctx, (&mockOperation{delay: 200 * time.Millisecond}).execute)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestExecutePropagatesContext(t *testing.T) {
+	cb := NewCircuitBreaker(Config{})
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	result, err := cb.Execute(ctx, func(ctx context.Context) (interface{}, error) {
+		return ctx.Value(key{}), nil
+	})
+	if err != nil || result != "value" {
+		t.Errorf("Expected operation to see the caller's context, got %v, %v", result, err)
+	}
+}
+
+func TestExecuteCancellation(t *testing.T) {
+	config := Config{
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 1
+		},
+	}
+
+	cb := NewCircuitBreaker(config)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := cb.Execute(ctx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, fmt.Errorf("request aborted: %w", ctx.Err())
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	if cb.GetState() != StateClosed {
+		t.Errorf("Expected cancellation not to trip the breaker, got %v", cb.GetState())
+	}
+	m := cb.GetMetrics()
+	if m.Cancellations != 1 || m.Requests != 0 || m.Failures != 0 {
+		t.Errorf("Expected 1 cancellation and no requests, got %+v", m)
+	}
+
+	// A dependency reporting context.Canceled on its own is still a failure.
+	cb.Execute(context.Background(), func(context.Context) (interface{}, error) {
+		return nil, context.Canceled
+	})
+	if cb.GetState() != StateOpen {
+		t.Errorf("Expected dependency error to trip the breaker, got %v", cb.GetState())
+	}
+}
+
+func TestExecuteCallerDeadline(t *testing.T) {
+	cb := NewCircuitBreaker(Config{
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 3
+		},
+	})
+
+	// A dependency that hangs until the caller gives up is a failure.
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		_, err := cb.Execute(ctx, func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	}
+
+	if cb.GetState() != StateOpen {
+		t.Errorf("Expected expired deadlines to trip the breaker, got %v", cb.GetState())
+	}
+	if m := cb.GetMetrics(); m.Cancellations != 0 || m.Failures != 3 {
+		t.Errorf("Expected 3 failures and no cancellations, got %+v", m)
+	}
+}
+
+func TestExecuteCancellationInHalfOpen(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	config := Config{
+		Clock:   clock,
+		Timeout: 50 * time.Millisecond,
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 1
+		},
+	}
+
+	cb := NewCircuitBreaker(config)
+	cb.Call(context.Background(), (&mockOperation{shouldFail: true}).execute)
+	clock.Advance(60 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cb.Execute(ctx, func(ctx context.Context) (interface{}, error) {
+		cancel()
+		return nil, ctx.Err()
+	})
+	if cb.GetState() != StateHalfOpen {
+		t.Fatalf("Expected breaker to stay Half-Open, got %v", cb.GetState())
+	}
+
+	// The cancelled probe must not consume the only half-open slot.
+	if _, err := cb.Call(context.Background(), (&mockOperation{}).execute); err != nil {
+		t.Fatalf("Expected probe to be admitted, got %v", err)
+	}
+	if cb.GetState() != StateClosed {
+		t.Errorf("Expected Closed after successful probe, got %v", cb.GetState())
+	}
+}
+
+func TestTypedBreaker(t *testing.T) {
+	b := NewBreaker[int](Config{
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 2
+		},
+	})
+	ctx := context.Background()
+
+	t.Run("Typed Results", func(t *testing.T) {
+		n, err := b.Call(ctx, func() (int, error) { return 42, nil })
+		if err != nil || n != 42 {
+			t.Errorf("Expected 42, got %d, %v", n, err)
+		}
+
+		n, err = b.Execute(ctx, func(context.Context) (int, error) { return 7, errors.New("boom") })
+		if err == nil || n != 0 {
+			t.Errorf("Expected zero value and error, got %d, %v", n, err)
+		}
+	})
+
+	t.Run("Shared State", func(t *testing.T) {
+		s := WrapBreaker[string](b.Unwrap())
+		s.Call(ctx, func() (string, error) { return "", errors.New("boom") })
+		if b.GetState() != StateOpen || s.GetState() != StateOpen {
+			t.Errorf("Expected both views Open, got %v and %v", b.GetState(), s.GetState())
+		}
+		if _, err := b.Call(ctx, func() (int, error) { return 1, nil }); !errors.Is(err, ErrCircuitBreakerOpen) {
+			t.Errorf("Expected ErrCircuitBreakerOpen, got %v", err)
+		}
+		if m := b.GetMetrics(); m.Failures != 2 {
+			t.Errorf("Expected 2 failures, got %d", m.Failures)
+		}
+	})
+
+	t.Run("Package Execute", func(t *testing.T) {
+		cb := NewCircuitBreaker(Config{})
+		type point struct{ X, Y int }
+		p, err := Execute(ctx, cb, func(context.Context) (point, error) { return point{1, 2}, nil })
+		if err != nil || p != (point{1, 2}) {
+			t.Errorf("Expected {1 2}, got %v, %v", p, err)
+		}
+
+		var nilPtr *point
+		got, err := Execute(ctx, cb, func(context.Context) (*point, error) { return nilPtr, nil })
+		if err != nil || got != nil {
+			t.Errorf("Expected nil pointer, got %v, %v", got, err)
+		}
+	})
+
+	t.Run("Fallback Type Mismatch", func(t *testing.T) {
+		fb := NewBreaker[int](Config{
+			ReadyToTrip: func(m Metrics) bool { return true },
+			Fallback: func(ctx context.Context, err error) (interface{}, error) {
+				return "cached", nil
+			},
+		})
+		fb.Call(ctx, func() (int, error) { return 0, errors.New("boom") })
+
+		n, err := fb.Call(ctx, func() (int, error) { return 1, nil })
+		if !errors.Is(err, ErrResultType) || n != 0 {
+			t.Errorf("Expected ErrResultType and zero value, got %d, %v", n, err)
+		}
+	})
+}
+
+func TestFailureClassification(t *testing.T) {
+	errNotFound := errors.New("not found")
+	errInvalid := errors.New("invalid argument")
+	errBackend := errors.New("backend unavailable")
+
+	config := Config{
+		IsSuccessful: func(err error) bool {
+			return err == nil || errors.Is(err, errNotFound)
+		},
+		IsIgnored: IgnoreErrors(errInvalid),
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 2
+		},
+	}
+
+	cb := NewCircuitBreaker(config)
+	ctx := context.Background()
+	fail := func(err error) func() (interface{}, error) {
+		return func() (interface{}, error) { return nil, err }
+	}
+
+	cb.Call(ctx, fail(errBackend))
+	if _, err := cb.Call(ctx, fail(errNotFound)); !errors.Is(err, errNotFound) {
+		t.Errorf("Expected business error to reach the caller, got %v", err)
+	}
+	cb.Call(ctx, fail(errBackend))
+	cb.Call(ctx, fail(fmt.Errorf("bad request: %w", errInvalid)))
+	if cb.GetState() != StateClosed {
+		t.Fatalf("Expected Closed, got %v", cb.GetState())
+	}
+
+	m := cb.GetMetrics()
+	if m.Requests != 3 || m.Successes != 1 || m.Failures != 2 || m.Ignored != 1 {
+		t.Errorf("Expected 3 requests, 1 success, 2 failures, 1 ignored, got %+v", m)
+	}
+	if m.ConsecutiveFailures != 1 {
+		t.Errorf("Expected ignored call to leave consecutive failures at 1, got %d", m.ConsecutiveFailures)
+	}
+
+	cb.Call(ctx, fail(errBackend))
+	if cb.GetState() != StateOpen {
+		t.Errorf("Expected Open after consecutive backend failures, got %v", cb.GetState())
+	}
+}
+
+func TestIgnoreErrors(t *testing.T) {
+	errA := errors.New("a")
+	errB := errors.New("b")
+	ignore := IgnoreErrors(errA, errB)
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"Nil", nil, false},
+		{"Direct", errA, true},
+		{"Wrapped", fmt.Errorf("wrap: %w", errB), true},
+		{"Other", errors.New("c"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ignore(tt.err); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStateString(t *testing.T) {
+	tests := map[State]string{
+		StateClosed:       "Closed",
+		StateOpen:         "Open",
+		StateHalfOpen:     "Half-Open",
+		StateForcedOpen:   "Forced-Open",
+		StateForcedClosed: "Forced-Closed",
+		StateDisabled:     "Disabled",
+		State(42):         "Unknown",
+	}
+	for state, want := range tests {
+		if got := state.String(); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestManualOverrides(t *testing.T) {
+	var changes []string
+	clock := NewFakeClock(time.Unix(0, 0))
+	config := Config{
+		Clock:   clock,
+		Timeout: 10 * time.Millisecond,
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 1
+		},
+		OnStateChange: func(name string, from State, to State) {
+			changes = append(changes, fmt.Sprintf("%v->%v", from, to))
+		},
+	}
+	ctx := context.Background()
+	failOp := &mockOperation{shouldFail: true}
+	successOp := &mockOperation{}
+
+	t.Run("Force Open", func(t *testing.T) {
+		cb := NewCircuitBreaker(config)
+		cb.ForceOpen()
+		clock.Advance(20 * time.Millisecond)
+
+		if _, err := cb.Call(ctx, successOp.execute); !errors.Is(err, ErrCircuitBreakerOpen) {
+			t.Errorf("Expected ErrCircuitBreakerOpen, got %v", err)
+		}
+		if cb.GetState() != StateForcedOpen {
+			t.Errorf("Expected Forced-Open to outlast Timeout, got %v", cb.GetState())
+		}
+	})
+
+	t.Run("Force Closed", func(t *testing.T) {
+		cb := NewCircuitBreaker(config)
+		cb.ForceClosed()
+		for i := 0; i < 3; i++ {
+			cb.Call(ctx, failOp.execute)
+		}
+
+		if cb.GetState() != StateForcedClosed {
+			t.Errorf("Expected Forced-Closed never to trip, got %v", cb.GetState())
+		}
+		if m := cb.GetMetrics(); m.Failures != 3 {
+			t.Errorf("Expected failures to be recorded, got %d", m.Failures)
+		}
+	})
+
+	t.Run("Disable", func(t *testing.T) {
+		cb := NewCircuitBreaker(config)
+		cb.Call(ctx, failOp.execute)
+		cb.Disable()
+
+		for i := 0; i < 3; i++ {
+			if _, err := cb.Call(ctx, successOp.execute); err != nil {
+				t.Fatalf("Expected disabled breaker to admit calls, got %v", err)
+			}
+			cb.Call(ctx, failOp.execute)
+		}
+		if cb.GetState() != StateDisabled {
+			t.Errorf("Expected Disabled, got %v", cb.GetState())
+		}
+		if m := cb.GetMetrics(); m.Requests != 1 {
+			t.Errorf("Expected disabled calls not to be recorded, got %d requests", m.Requests)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		changes = nil
+		cb := NewCircuitBreaker(config)
+		cb.Call(ctx, failOp.execute)
+		cb.Reset()
+		if cb.GetState() != StateClosed || cb.GetMetrics().Requests != 0 {
+			t.Errorf("Expected Closed with cleared metrics, got %v %+v", cb.GetState(), cb.GetMetrics())
+		}
+
+		cb.Call(ctx, successOp.execute)
+		cb.Reset()
+		if m := cb.GetMetrics(); m.Requests != 0 {
+			t.Errorf("Expected Reset to clear metrics while Closed, got %+v", m)
+		}
+
+		cb.ForceOpen()
+		cb.Reset()
+		want := []string{"Closed->Open", "Open->Closed", "Closed->Forced-Open", "Forced-Open->Closed"}
+		if fmt.Sprint(changes) != fmt.Sprint(want) {
+			t.Errorf("Expected transitions %v, got %v", want, changes)
+		}
+	})
+}
+
+func TestOpenBackoff(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	config := Config{
+		Clock:       clock,
+		Timeout:     time.Hour, // ignored once OpenBackoff is set
+		OpenBackoff: ExponentialBackoff{Initial: 20 * time.Millisecond, Max: 60 * time.Millisecond},
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 1
+		},
+	}
+
+	cb := NewCircuitBreaker(config)
+	ctx := context.Background()
+	failOp := &mockOperation{shouldFail: true}
+	successOp := &mockOperation{}
+
+	// Each failed probe reopens the breaker for twice as long, up to Max.
+	cb.Call(ctx, failOp.execute)
+	for _, want := range []time.Duration{20, 40, 60, 60} {
+		want *= time.Millisecond
+		if got := cb.GetMetrics().OpenDuration; got != want {
+			t.Fatalf("Expected open duration %v, got %v", want, got)
+		}
+		clock.Advance(want + 10*time.Millisecond)
+		cb.Call(ctx, failOp.execute)
+		if cb.GetState() != StateOpen {
+			t.Fatalf("Expected Open after failed probe, got %v", cb.GetState())
+		}
+	}
+
+	// A successful close resets the backoff.
+	clock.Advance(70 * time.Millisecond)
+	cb.Call(ctx, successOp.execute)
+	if cb.GetState() != StateClosed {
+		t.Fatalf("Expected Closed, got %v", cb.GetState())
+	}
+	if got := cb.GetMetrics().OpenDuration; got != 0 {
+		t.Errorf("Expected no open duration while Closed, got %v", got)
+	}
+	cb.Call(ctx, failOp.execute)
+	if got := cb.GetMetrics().OpenDuration; got != 20*time.Millisecond {
+		t.Errorf("Expected backoff to restart at 20ms, got %v", got)
+	}
+}
+
+func TestSuccessThreshold(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	config := Config{
+		Clock:            clock,
+		Timeout:          50 * time.Millisecond,
+		SuccessThreshold: 3,
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 1
+		},
+	}
+
+	cb := NewCircuitBreaker(config)
+	ctx := context.Background()
+	successOp := &mockOperation{}
+
+	cb.Call(ctx, (&mockOperation{shouldFail: true}).execute)
+	clock.Advance(60 * time.Millisecond)
+
+	// MaxRequests is 1, so sequential probes must each free their slot.
+	for i := 0; i < 2; i++ {
+		if _, err := cb.Call(ctx, successOp.execute); err != nil {
+			t.Fatalf("Probe %d: expected success, got %v", i, err)
+		}
+		if cb.GetState() != StateHalfOpen {
+			t.Fatalf("Probe %d: expected Half-Open below the threshold, got %v", i, cb.GetState())
+		}
+	}
+
+	cb.Call(ctx, successOp.execute)
+	if cb.GetState() != StateClosed {
+		t.Errorf("Expected Closed after 3 successes, got %v", cb.GetState())
+	}
+}
+
+func TestSuccessThresholdResetOnFailure(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	config := Config{
+		Clock:            clock,
+		Timeout:          50 * time.Millisecond,
+		SuccessThreshold: 2,
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 1
+		},
+	}
+
+	cb := NewCircuitBreaker(config)
+	ctx := context.Background()
+	failOp := &mockOperation{shouldFail: true}
+	successOp := &mockOperation{}
+
+	cb.Call(ctx, failOp.execute)
+	clock.Advance(60 * time.Millisecond)
+	cb.Call(ctx, successOp.execute)
+	cb.Call(ctx, failOp.execute)
+	if cb.GetState() != StateOpen {
+		t.Fatalf("Expected Open after a failed probe, got %v", cb.GetState())
+	}
+
+	clock.Advance(60 * time.Millisecond)
+	cb.Call(ctx, successOp.execute)
+	if cb.GetState() != StateHalfOpen {
+		t.Errorf("Expected success count to restart in the new half-open period, got %v", cb.GetState())
+	}
+}
+
+func TestRampUp(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	config := Config{
+		Clock:          clock,
+		Timeout:        20 * time.Millisecond,
+		RampUpDuration: 200 * time.Millisecond,
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 1
+		},
+	}
+
+	cb := NewCircuitBreaker(config)
+	ctx := context.Background()
+	successOp := &mockOperation{}
+
+	cb.Call(ctx, (&mockOperation{shouldFail: true}).execute)
+	clock.Advance(30 * time.Millisecond)
+	cb.Call(ctx, successOp.execute)
+	if cb.GetState() != StateClosed {
+		t.Fatalf("Expected Closed, got %v", cb.GetState())
+	}
+
+	admitted := 0
+	for i := 0; i < 100; i++ {
+		_, err := cb.Call(ctx, successOp.execute)
+		switch {
+		case err == nil:
+			admitted++
+		case !errors.Is(err, ErrRampingUp):
+			t.Fatalf("Expected ErrRampingUp, got %v", err)
+		}
+	}
+	if admitted != 10 {
+		t.Errorf("Expected 10%% of traffic at the start of the ramp-up, admitted %d/100", admitted)
+	}
+
+	clock.Advance(150 * time.Millisecond)
+	admitted = 0
+	for i := 0; i < 100; i++ {
+		if _, err := cb.Call(ctx, successOp.execute); err == nil {
+			admitted++
+		}
+	}
+	if admitted < 70 || admitted > 80 {
+		t.Errorf("Expected about 75%% of traffic three quarters into the ramp-up, admitted %d/100", admitted)
+	}
+
+	clock.Advance(50 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		if _, err := cb.Call(ctx, successOp.execute); err != nil {
+			t.Fatalf("Expected full traffic after the ramp-up, got %v", err)
+		}
+	}
+}
+
+func TestRampUpSkippedOnReset(t *testing.T) {
+	cb := NewCircuitBreaker(Config{RampUpDuration: time.Hour})
+	cb.ForceOpen()
+	cb.Reset()
+
+	for i := 0; i < 10; i++ {
+		if _, err := cb.Call(context.Background(), (&mockOperation{}).execute); err != nil {
+			t.Fatalf("Expected manual reset to skip the ramp-up, got %v", err)
+		}
+	}
+}
+
+func TestFallback(t *testing.T) {
+	errBackend := errors.New("backend unavailable")
+	var seen []error
+	fallback := func(ctx context.Context, err error) (interface{}, error) {
+		seen = append(seen, err)
+		return "cached", nil
+	}
+	ctx := context.Background()
+	failOp := &mockOperation{shouldFail: true}
+
+	t.Run("Open Circuit", func(t *testing.T) {
+		seen = nil
+		cb := NewCircuitBreaker(Config{
+			Fallback: fallback,
+			ReadyToTrip: func(m Metrics) bool {
+				return m.ConsecutiveFailures >= 1
+			},
+		})
+
+		if _, err := cb.Call(ctx, failOp.execute); err == nil {
+			t.Error("Expected failure to reach the caller without FallbackOnFailure")
+		}
+		result, err := cb.Call(ctx, failOp.execute)
+		if err != nil || result != "cached" {
+			t.Errorf("Expected fallback result, got %v, %v", result, err)
+		}
+		if len(seen) != 1 || !errors.Is(seen[0], ErrCircuitBreakerOpen) {
+			t.Errorf("Expected fallback to see ErrCircuitBreakerOpen, got %v", seen)
+		}
+		if m := cb.GetMetrics(); m.Fallbacks != 1 {
+			t.Errorf("Expected 1 fallback, got %d", m.Fallbacks)
+		}
+	})
+
+	t.Run("Too Many Requests", func(t *testing.T) {
+		seen = nil
+		clock := NewFakeClock(time.Unix(0, 0))
+		cb := NewCircuitBreaker(Config{
+			Clock:    clock,
+			Timeout:  time.Second,
+			Fallback: fallback,
+			ReadyToTrip: func(m Metrics) bool {
+				return m.ConsecutiveFailures >= 1
+			},
+		})
+		cb.Call(ctx, failOp.execute)
+		clock.Advance(time.Second)
+
+		probe := newBlockingOperation()
+		go cb.Execute(ctx, probe.execute)
+		<-probe.started
+		defer close(probe.release)
+
+		if result, _ := cb.Call(ctx, failOp.execute); result != "cached" {
+			t.Errorf("Expected fallback result, got %v", result)
+		}
+		if len(seen) != 1 || !errors.Is(seen[0], ErrTooManyRequests) {
+			t.Errorf("Expected fallback to see ErrTooManyRequests, got %v", seen)
+		}
+	})
+
+	t.Run("On Failure", func(t *testing.T) {
+		seen = nil
+		cb := NewCircuitBreaker(Config{
+			Fallback:          fallback,
+			FallbackOnFailure: true,
+			ReadyToTrip: func(m Metrics) bool {
+				return false
+			},
+		})
+
+		result, err := cb.Call(ctx, func() (interface{}, error) { return nil, errBackend })
+		if err != nil || result != "cached" {
+			t.Errorf("Expected fallback result, got %v, %v", result, err)
+		}
+		if len(seen) != 1 || !errors.Is(seen[0], errBackend) {
+			t.Errorf("Expected fallback to see the operation error, got %v", seen)
+		}
+		m := cb.GetMetrics()
+		if m.Failures != 1 || m.Fallbacks != 1 {
+			t.Errorf("Expected the failure to be recorded and answered, got %+v", m)
+		}
+
+		if result, _ := cb.Call(ctx, (&mockOperation{}).execute); result != "success" {
+			t.Errorf("Expected successful calls to bypass the fallback, got %v", result)
+		}
+	})
+
+	t.Run("Fallback Error", func(t *testing.T) {
+		errFallback := errors.New("no cached value")
+		cb := NewCircuitBreaker(Config{
+			Fallback: func(ctx context.Context, err error) (interface{}, error) {
+				return nil, errFallback
+			},
+		})
+		cb.ForceOpen()
+		if _, err := cb.Call(ctx, failOp.execute); !errors.Is(err, errFallback) {
+			t.Errorf("Expected the fallback error, got %v", err)
+		}
+	})
+}
+
+func TestStaleOutcomesIgnored(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	config := Config{
+		Clock:   clock,
+		Timeout: time.Second,
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 1
+		},
+	}
+	ctx := context.Background()
+
+	for _, staleFails := range []bool{false, true} {
+		name := "Stale Success"
+		if staleFails {
+			name = "Stale Failure"
+		}
+		t.Run(name, func(t *testing.T) {
+			cb := NewCircuitBreaker(config)
+
+			// A slow call admitted while Closed...
+			stale := newBlockingOperation()
+			staleDone := make(chan struct{})
+			go func() {
+				defer close(staleDone)
+				cb.Execute(ctx, func(ctx context.Context) (interface{}, error) {
+					stale.execute(ctx)
+					if staleFails {
+						return nil, errors.New("stale failure")
+					}
+					return "stale", nil
+				})
+			}()
+			<-stale.started
+
+			// ...outlives a trip and the start of a half-open probe.
+			cb.Call(ctx, (&mockOperation{shouldFail: true}).execute)
+			clock.Advance(time.Second)
+			probe := newBlockingOperation()
+			probeDone := make(chan struct{})
+			go func() {
+				defer close(probeDone)
+				cb.Execute(ctx, probe.execute)
+			}()
+			<-probe.started
+
+			close(stale.release)
+			<-staleDone
+			if cb.GetState() != StateHalfOpen {
+				t.Errorf("Expected the stale outcome to leave the breaker Half-Open, got %v", cb.GetState())
+			}
+
+			close(probe.release)
+			<-probeDone
+			if cb.GetState() != StateClosed {
+				t.Errorf("Expected the probe to close the breaker, got %v", cb.GetState())
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import "context"
+
+// Policy runs operations under some resilience rule. CircuitBreaker,
+// *Retrier and *Bulkhead are all policies.
+type Policy interface {
+	Execute(ctx context.Context, operation func(ctx context.Context) (interface{}, error)) (interface{}, error)
+}
+
+// policyChain nests its policies, the first one outermost
+type policyChain []Policy
+
+// Chain combines policies into one, with policies[0] outermost. A typical
+// order is Chain(bulkhead, retrier, breaker): the bulkhead bounds whole retry
+// sequences, and every attempt is recorded by the breaker, which makes the
+// retrier stop as soon as it opens.
+func Chain(policies ...Policy) Policy {
+	return policyChain(policies)
+}
+
+// Execute runs operation through every policy in the chain
+func (c policyChain) Execute(ctx context.Context, operation func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if len(c) == 0 {
+		return operation(ctx)
+	}
+	return c[0].Execute(ctx, func(ctx context.Context) (interface{}, error) {
+		return c[1:].Execute(ctx, operation)
+	})
+}
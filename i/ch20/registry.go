@@ -0,0 +1,148 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// Registry errors
+var (
+	ErrBreakerNotFound = errors.New("circuit breaker not found")
+	ErrInvalidState    = errors.New("invalid circuit breaker state")
+)
+
+// BreakerSnapshot is a point-in-time view of a registered breaker
+type BreakerSnapshot struct {
+	Name    string
+	State   State
+	Metrics Metrics
+}
+
+// Registry creates and tracks circuit breakers by name. Breakers are built
+// from a shared default Config, optionally adjusted per name.
+type Registry struct {
+	mu        sync.Mutex
+	defaults  Config
+	overrides map[string]func(*Config)
+	breakers  map[string]*circuitBreakerImpl
+}
+
+// NewRegistry creates an empty registry whose breakers start from defaults
+func NewRegistry(defaults Config) *Registry {
+	return &Registry{
+		defaults:  defaults,
+		overrides: make(map[string]func(*Config)),
+		breakers:  make(map[string]*circuitBreakerImpl),
+	}
+}
+
+// Configure registers an override applied on top of the defaults when the
+// breaker called name is created. Breakers that already exist are unaffected.
+func (r *Registry) Configure(name string, override func(*Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides[name] = override
+}
+
+// Get returns the breaker called name, creating it on first use
+func (r *Registry) Get(name string) CircuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cb, ok := r.breakers[name]; ok {
+		return cb
+	}
+
+	config := r.defaults
+	if override, ok := r.overrides[name]; ok {
+		override(&config)
+	}
+	config.Name = name
+
+	cb := newCircuitBreaker(config)
+	r.breakers[name] = cb
+	return cb
+}
+
+// Lookup returns the breaker called name without creating it
+func (r *Registry) Lookup(name string) (CircuitBreaker, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cb, ok := r.breakers[name]
+	if !ok {
+		return nil, false
+	}
+	return cb, true
+}
+
+// Remove drops the breaker called name and reports whether it existed.
+// A later Get creates a fresh breaker.
+func (r *Registry) Remove(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.breakers[name]; !ok {
+		return false
+	}
+	delete(r.breakers, name)
+	return true
+}
+
+// Names returns the names of all registered breakers in sorted order
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.breakers))
+	for name := range r.breakers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Snapshot returns the state and metrics of every registered breaker,
+// sorted by name
+func (r *Registry) Snapshot() []BreakerSnapshot {
+	r.mu.Lock()
+	breakers := make([]*circuitBreakerImpl, 0, len(r.breakers))
+	for _, cb := range r.breakers {
+		breakers = append(breakers, cb)
+	}
+	r.mu.Unlock()
+
+	snapshots := make([]BreakerSnapshot, 0, len(breakers))
+	for _, cb := range breakers {
+		snapshots = append(snapshots, BreakerSnapshot{
+			Name:    cb.name,
+			State:   cb.GetState(),
+			Metrics: cb.GetMetrics(),
+		})
+	}
+	slices.SortFunc(snapshots, func(a, b BreakerSnapshot) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return snapshots
+}
+
+// Transition forces the breaker called name into state, notifying its
+// OnStateChange callback. Forcing Closed clears its metrics; forcing Open
+// restarts its Timeout. The manual override states behave as set by
+// ForceOpen, ForceClosed and Disable.
+func (r *Registry) Transition(name string, state State) error {
+	switch state {
+	case StateClosed, StateOpen, StateHalfOpen, StateForcedOpen, StateForcedClosed, StateDisabled:
+	default:
+		return ErrInvalidState
+	}
+
+	r.mu.Lock()
+	cb, ok := r.breakers[name]
+	r.mu.Unlock()
+	if !ok {
+		return ErrBreakerNotFound
+	}
+
+	cb.transition(state)
+	return nil
+}
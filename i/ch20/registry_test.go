@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfigName(t *testing.T) {
+	var names []string
+	record := func(name string, from, to State) { names = append(names, name) }
+
+	failOp := &mockOperation{shouldFail: true}
+	trip := func(m Metrics) bool { return m.ConsecutiveFailures >= 1 }
+
+	NewCircuitBreaker(Config{ReadyToTrip: trip, OnStateChange: record}).Call(context.Background(), failOp.execute)
+	NewCircuitBreaker(Config{Name: "payments", ReadyToTrip: trip, OnStateChange: record}).Call(context.Background(), failOp.execute)
+
+	if want := []string{"circuit-breaker", "payments"}; !slices.Equal(names, want) {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	var mu sync.Mutex
+	changes := make(map[string][]State)
+
+	r := NewRegistry(Config{
+		Timeout: time.Minute,
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 3
+		},
+		OnStateChange: func(name string, from, to State) {
+			mu.Lock()
+			changes[name] = append(changes[name], to)
+			mu.Unlock()
+		},
+	})
+	r.Configure("fragile", func(c *Config) {
+		c.ReadyToTrip = func(m Metrics) bool { return m.ConsecutiveFailures >= 1 }
+	})
+	ctx := context.Background()
+	failOp := &mockOperation{shouldFail: true}
+
+	t.Run("Get Or Create", func(t *testing.T) {
+		if _, ok := r.Lookup("users"); ok {
+			t.Fatal("Expected Lookup not to create breakers")
+		}
+		a := r.Get("users")
+		if b := r.Get("users"); a != b {
+			t.Error("Expected Get to return the same breaker for a name")
+		}
+		if got, ok := r.Lookup("users"); !ok || got != a {
+			t.Error("Expected Lookup to find the created breaker")
+		}
+	})
+
+	t.Run("Per Name Overrides", func(t *testing.T) {
+		r.Get("users").Call(ctx, failOp.execute)
+		r.Get("fragile").Call(ctx, failOp.execute)
+
+		if s := r.Get("users").GetState(); s != StateClosed {
+			t.Errorf("Expected users to use the default threshold and stay Closed, got %v", s)
+		}
+		if s := r.Get("fragile").GetState(); s != StateOpen {
+			t.Errorf("Expected fragile to use its override and open, got %v", s)
+		}
+		mu.Lock()
+		got := changes["fragile"]
+		mu.Unlock()
+		if !slices.Equal(got, []State{StateOpen}) {
+			t.Errorf("Expected callback named fragile with [Open], got %v", got)
+		}
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		snaps := r.Snapshot()
+		if len(snaps) != 2 || snaps[0].Name != "fragile" || snaps[1].Name != "users" {
+			t.Fatalf("Expected sorted snapshots for fragile and users, got %+v", snaps)
+		}
+		if snaps[0].State != StateOpen || snaps[1].Metrics.Failures != 1 {
+			t.Errorf("Expected snapshot to carry state and metrics, got %+v", snaps)
+		}
+		if names := r.Names(); !slices.Equal(names, []string{"fragile", "users"}) {
+			t.Errorf("Expected [fragile users], got %v", names)
+		}
+	})
+
+	t.Run("Transition", func(t *testing.T) {
+		if err := r.Transition("fragile", StateClosed); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if s := r.Get("fragile").GetState(); s != StateClosed {
+			t.Errorf("Expected Closed, got %v", s)
+		}
+		if err := r.Transition("users", StateOpen); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := r.Get("users").Call(ctx, (&mockOperation{}).execute); !errors.Is(err, ErrCircuitBreakerOpen) {
+			t.Errorf("Expected forced Open to reject calls, got %v", err)
+		}
+		if err := r.Transition("missing", StateOpen); !errors.Is(err, ErrBreakerNotFound) {
+			t.Errorf("Expected ErrBreakerNotFound, got %v", err)
+		}
+		if err := r.Transition("users", State(42)); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("Expected ErrInvalidState, got %v", err)
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		old := r.Get("users")
+		if !r.Remove("users") || r.Remove("users") {
+			t.Error("Expected Remove to succeed exactly once")
+		}
+		if r.Get("users") == old {
+			t.Error("Expected a fresh breaker after Remove")
+		}
+	})
+}
+
+func TestRegistryConcurrentGet(t *testing.T) {
+	r := NewRegistry(Config{})
+	var wg sync.WaitGroup
+	results := make([]CircuitBreaker, 16)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = r.Get("shared")
+		}(i)
+	}
+	wg.Wait()
+
+	for _, cb := range results {
+		if cb != results[0] {
+			t.Fatal("Expected every goroutine to get the same breaker")
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RetryConfig represents the configuration for a Retrier
+type RetryConfig struct {
+	MaxAttempts int                                               // Total attempts including the first, 3 by default
+	Backoff     Backoff                                           // Delay before each retry, exponential from 100ms by default
+	IsRetryable func(err error) bool                              // Reports whether a failed attempt may be retried; all errors by default
+	OnRetry     func(attempt int, err error, delay time.Duration) // Called before sleeping ahead of a retry
+}
+
+// Retrier re-runs failed operations with backoff. It never retries a call
+// rejected by a circuit breaker, since the breaker will keep rejecting it
+// until it leaves the open state.
+type Retrier struct {
+	config RetryConfig
+}
+
+// NewRetrier creates a retrier with the given configuration
+func NewRetrier(config RetryConfig) *Retrier {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.Backoff == nil {
+		config.Backoff = ExponentialBackoff{Initial: 100 * time.Millisecond, Max: 10 * time.Second, Jitter: 0.2}
+	}
+	if config.IsRetryable == nil {
+		config.IsRetryable = func(error) bool { return true }
+	}
+	return &Retrier{config: config}
+}
+
+// Execute runs operation until it succeeds, fails with a non-retryable error
+// or MaxAttempts is reached, returning the last error. The wait between
+// attempts is cut short when ctx is done, in which case ctx.Err() is returned.
+//
+// Execute has the same shape as CircuitBreaker.Execute, so a Retrier can wrap
+// a breaker (each attempt is counted by the breaker) or be wrapped by one
+// (the whole retry sequence counts as a single call).
+func (r *Retrier) Execute(ctx context.Context, operation func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result, err := operation(ctx)
+		if err == nil {
+			return result, nil
+		}
+		if attempt+1 >= r.config.MaxAttempts || isBreakerRejection(err) || !r.config.IsRetryable(err) {
+			return nil, err
+		}
+
+		delay := r.config.Backoff.Duration(attempt)
+		if r.config.OnRetry != nil {
+			r.config.OnRetry(attempt+1, err, delay)
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Call runs an operation that does not take a context
+func (r *Retrier) Call(ctx context.Context, operation func() (interface{}, error)) (interface{}, error) {
+	return r.Execute(ctx, func(context.Context) (interface{}, error) {
+		return operation()
+	})
+}
+
+// isBreakerRejection reports whether err means a circuit breaker refused
+// the call without running it
+func isBreakerRejection(err error) bool {
+	return errors.Is(err, ErrCircuitBreakerOpen) ||
+		errors.Is(err, ErrTooManyRequests) ||
+		errors.Is(err, ErrRampingUp)
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
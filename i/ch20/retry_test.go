@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakyOperation fails the first failures calls with err, then succeeds
+type flakyOperation struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyOperation) execute(context.Context) (interface{}, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+	return "success", nil
+}
+
+func TestRetrier(t *testing.T) {
+	errTransient := errors.New("transient")
+	errPermanent := errors.New("permanent")
+	ctx := context.Background()
+
+	newRetrier := func() *Retrier {
+		return NewRetrier(RetryConfig{
+			MaxAttempts: 3,
+			Backoff:     ConstantBackoff(time.Millisecond),
+			IsRetryable: func(err error) bool { return !errors.Is(err, errPermanent) },
+		})
+	}
+
+	t.Run("Succeeds After Retries", func(t *testing.T) {
+		op := &flakyOperation{failures: 2, err: errTransient}
+		result, err := newRetrier().Execute(ctx, op.execute)
+		if err != nil || result != "success" {
+			t.Errorf("Expected success, got %v, %v", result, err)
+		}
+		if op.calls != 3 {
+			t.Errorf("Expected 3 attempts, got %d", op.calls)
+		}
+	})
+
+	t.Run("Exhausts Attempts", func(t *testing.T) {
+		op := &flakyOperation{failures: 5, err: errTransient}
+		if _, err := newRetrier().Execute(ctx, op.execute); !errors.Is(err, errTransient) {
+			t.Errorf("Expected the last error, got %v", err)
+		}
+		if op.calls != 3 {
+			t.Errorf("Expected 3 attempts, got %d", op.calls)
+		}
+	})
+
+	t.Run("Non Retryable", func(t *testing.T) {
+		op := &flakyOperation{failures: 5, err: errPermanent}
+		newRetrier().Execute(ctx, op.execute)
+		if op.calls != 1 {
+			t.Errorf("Expected 1 attempt, got %d", op.calls)
+		}
+	})
+
+	t.Run("Breaker Open", func(t *testing.T) {
+		op := &flakyOperation{failures: 5, err: ErrCircuitBreakerOpen}
+		newRetrier().Execute(ctx, op.execute)
+		if op.calls != 1 {
+			t.Errorf("Expected no retries on an open breaker, got %d attempts", op.calls)
+		}
+	})
+
+	t.Run("On Retry", func(t *testing.T) {
+		var attempts []int
+		r := NewRetrier(RetryConfig{
+			Backoff: ExponentialBackoff{Initial: time.Millisecond},
+			OnRetry: func(attempt int, err error, delay time.Duration) {
+				attempts = append(attempts, attempt)
+				if want := time.Millisecond << (attempt - 1); delay != want {
+					t.Errorf("Attempt %d: expected delay %v, got %v", attempt, want, delay)
+				}
+			},
+		})
+		r.Execute(ctx, (&flakyOperation{failures: 5, err: errTransient}).execute)
+		if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+			t.Errorf("Expected retries [1 2], got %v", attempts)
+		}
+	})
+}
+
+func TestRetrierContext(t *testing.T) {
+	r := NewRetrier(RetryConfig{MaxAttempts: 10, Backoff: ConstantBackoff(time.Hour)})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	op := &flakyOperation{failures: 10, err: errors.New("transient")}
+	start := time.Now()
+	if _, err := r.Execute(ctx, op.execute); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected cancellation to interrupt the backoff, took %v", elapsed)
+	}
+	if op.calls != 1 {
+		t.Errorf("Expected 1 attempt, got %d", op.calls)
+	}
+}
+
+func TestRetryWithBreaker(t *testing.T) {
+	ctx := context.Background()
+	errTransient := errors.New("transient")
+	retrier := NewRetrier(RetryConfig{MaxAttempts: 5, Backoff: ConstantBackoff(0)})
+	trip := func(m Metrics) bool { return m.ConsecutiveFailures >= 2 }
+
+	t.Run("Retry Outside Breaker", func(t *testing.T) {
+		cb := NewCircuitBreaker(Config{ReadyToTrip: trip})
+		op := &flakyOperation{failures: 10, err: errTransient}
+
+		_, err := retrier.Execute(ctx, func(ctx context.Context) (interface{}, error) {
+			return cb.Execute(ctx, op.execute)
+		})
+		if !errors.Is(err, ErrCircuitBreakerOpen) {
+			t.Errorf("Expected ErrCircuitBreakerOpen, got %v", err)
+		}
+		if op.calls != 2 {
+			t.Errorf("Expected retries to stop once the breaker opened, got %d calls", op.calls)
+		}
+	})
+
+	t.Run("Breaker Outside Retry", func(t *testing.T) {
+		cb := NewCircuitBreaker(Config{ReadyToTrip: trip})
+		op := &flakyOperation{failures: 3, err: errTransient}
+
+		result, err := cb.Execute(ctx, func(ctx context.Context) (interface{}, error) {
+			return retrier.Execute(ctx, op.execute)
+		})
+		if err != nil || result != "success" {
+			t.Errorf("Expected success, got %v, %v", result, err)
+		}
+		if m := cb.GetMetrics(); m.Requests != 1 || m.Failures != 0 {
+			t.Errorf("Expected the retry sequence to count as one successful call, got %+v", m)
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SharedState is what a breaker publishes to a StateStore when it trips or
+// recovers, so that breakers with the same name in other replicas follow
+type SharedState struct {
+	State     State     `json:"state"`                // StateOpen or StateClosed
+	OpenUntil time.Time `json:"open_until,omitempty"` // End of the open period when State is StateOpen
+	UpdatedAt time.Time `json:"updated_at"`           // When the publishing breaker changed state
+}
+
+// StateStore shares breaker state between replicas, keyed by Config.Name
+type StateStore interface {
+	Load(name string) (SharedState, bool, error)
+	Store(name string, state SharedState) error
+}
+
+// --- In-memory store ---
+
+// MemoryStateStore is a StateStore for breakers within one process
+type MemoryStateStore struct {
+	mu     sync.Mutex
+	states map[string]SharedState
+}
+
+// NewMemoryStateStore creates an empty in-memory store
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{states: make(map[string]SharedState)}
+}
+
+// Load returns the state last stored for name
+func (s *MemoryStateStore) Load(name string) (SharedState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[name]
+	return state, ok, nil
+}
+
+// Store records state for name
+func (s *MemoryStateStore) Store(name string, state SharedState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[name] = state
+	return nil
+}
+
+// --- File-backed store ---
+
+// FileStateStore keeps one JSON file per breaker in a directory, which
+// replicas share through a common volume. Files are replaced atomically, so
+// readers never see a partial write.
+type FileStateStore struct {
+	dir string
+}
+
+// NewFileStateStore creates a store in dir, creating the directory if needed
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStateStore{dir: dir}, nil
+}
+
+// Load reads the state last stored for name
+func (s *FileStateStore) Load(name string) (SharedState, bool, error) {
+	var state SharedState
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, false, nil
+	}
+	if err != nil {
+		return state, false, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, false, err
+	}
+	return state, true, nil
+}
+
+// Store writes state for name
+func (s *FileStateStore) Store(name string, state SharedState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".breaker-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(name))
+}
+
+func (s *FileStateStore) path(name string) string {
+	return filepath.Join(s.dir, url.PathEscape(name)+".json")
+}
+
+// --- Breaker integration ---
+
+// syncState adopts state published by other replicas, at most once per
+// Config.StateSyncInterval. Only Closed and Open breakers follow the store;
+// half-open probes and manual overrides stay local.
+func (cb *circuitBreakerImpl) syncState() {
+	if cb.config.StateStore == nil {
+		return
+	}
+
+	cb.mutex.Lock()
+	now := cb.config.Clock.Now()
+	stale := now.Sub(cb.lastSync) >= cb.config.StateSyncInterval
+	if !stale || (cb.state != StateClosed && cb.state != StateOpen) {
+		cb.mutex.Unlock()
+		return
+	}
+	cb.lastSync = now
+	cb.mutex.Unlock()
+
+	shared, ok, err := cb.config.StateStore.Load(cb.name)
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	if err != nil {
+		cb.metrics.StoreErrors++
+		return
+	}
+	if !ok || !shared.UpdatedAt.After(cb.lastStateChange) {
+		return
+	}
+
+	cb.adopting = true
+	defer func() { cb.adopting = false }()
+
+	switch {
+	case cb.state == StateClosed && shared.State == StateOpen && now.Before(shared.OpenUntil):
+		cb.setStateLocked(StateOpen)
+		cb.openDuration = shared.OpenUntil.Sub(now)
+	case cb.state == StateOpen && shared.State == StateClosed:
+		cb.setStateLocked(StateClosed)
+	}
+}
+
+// queuePublishLocked prepares a trip or recovery for publishing once the
+// mutex is released. Transitions adopted from the store are not echoed back.
+// Must be called with cb.mutex held.
+func (cb *circuitBreakerImpl) queuePublishLocked(newState State) {
+	if cb.config.StateStore == nil || cb.adopting {
+		return
+	}
+
+	switch newState {
+	case StateOpen:
+		cb.pending = &SharedState{
+			State:     StateOpen,
+			OpenUntil: cb.lastStateChange.Add(cb.openDuration),
+			UpdatedAt: cb.lastStateChange,
+		}
+	case StateClosed:
+		cb.pending = &SharedState{State: StateClosed, UpdatedAt: cb.lastStateChange}
+	}
+}
+
+// publishPending stores the last queued transition, outside the mutex
+func (cb *circuitBreakerImpl) publishPending() {
+	if cb.config.StateStore == nil {
+		return
+	}
+
+	cb.mutex.Lock()
+	pending := cb.pending
+	cb.pending = nil
+	cb.mutex.Unlock()
+	if pending == nil {
+		return
+	}
+
+	if err := cb.config.StateStore.Store(cb.name, *pending); err != nil {
+		cb.mutex.Lock()
+		cb.metrics.StoreErrors++
+		cb.mutex.Unlock()
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingStore counts Load calls on a wrapped store
+type countingStore struct {
+	StateStore
+	loads atomic.Int32
+}
+
+func (s *countingStore) Load(name string) (SharedState, bool, error) {
+	s.loads.Add(1)
+	return s.StateStore.Load(name)
+}
+
+// failingStore fails every operation
+type failingStore struct{}
+
+func (failingStore) Load(string) (SharedState, bool, error) {
+	return SharedState{}, false, errors.New("store unavailable")
+}
+
+func (failingStore) Store(string, SharedState) error { return errors.New("store unavailable") }
+
+func TestSharedState(t *testing.T) {
+	stores := map[string]func(t *testing.T) StateStore{
+		"Memory": func(t *testing.T) StateStore { return NewMemoryStateStore() },
+		"File": func(t *testing.T) StateStore {
+			store, err := NewFileStateStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create file store: %v", err)
+			}
+			return store
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			clock := NewFakeClock(time.Unix(1000, 0))
+			store := newStore(t)
+			newReplica := func() CircuitBreaker {
+				return NewCircuitBreaker(Config{
+					Name:              "payments",
+					Clock:             clock,
+					Timeout:           time.Minute,
+					StateStore:        store,
+					StateSyncInterval: time.Second,
+					ReadyToTrip: func(m Metrics) bool {
+						return m.ConsecutiveFailures >= 2
+					},
+				})
+			}
+			a, b := newReplica(), newReplica()
+			ctx := context.Background()
+			failOp := &mockOperation{shouldFail: true}
+			successOp := &mockOperation{}
+
+			// b syncs once while the dependency is still healthy.
+			b.Call(ctx, successOp.execute)
+
+			clock.Advance(time.Millisecond)
+			a.Call(ctx, failOp.execute)
+			a.Call(ctx, failOp.execute)
+			if a.GetState() != StateOpen {
+				t.Fatalf("Expected a to trip, got %v", a.GetState())
+			}
+
+			clock.Advance(time.Second)
+			calls := failOp.getCallCount()
+			if _, err := b.Call(ctx, failOp.execute); !errors.Is(err, ErrCircuitBreakerOpen) {
+				t.Fatalf("Expected b to follow a's trip, got %v", err)
+			}
+			if failOp.getCallCount() != calls {
+				t.Error("Expected b not to reach the dependency")
+			}
+			if got := b.GetMetrics().OpenDuration; got != 59*time.Second {
+				t.Errorf("Expected b to open for the rest of a's period, got %v", got)
+			}
+
+			// a recovers first and b follows without probing itself.
+			clock.Advance(59 * time.Second)
+			if _, err := a.Call(ctx, successOp.execute); err != nil || a.GetState() != StateClosed {
+				t.Fatalf("Expected a to close after a probe, got %v, %v", a.GetState(), err)
+			}
+			clock.Advance(time.Second)
+			if _, err := b.Call(ctx, successOp.execute); err != nil {
+				t.Fatalf("Expected b to admit calls, got %v", err)
+			}
+			if b.GetState() != StateClosed {
+				t.Errorf("Expected b to follow a's recovery, got %v", b.GetState())
+			}
+		})
+	}
+}
+
+func TestSharedStateSyncInterval(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	store := &countingStore{StateStore: NewMemoryStateStore()}
+	cb := NewCircuitBreaker(Config{Clock: clock, StateStore: store, StateSyncInterval: time.Second})
+	ctx := context.Background()
+	op := &mockOperation{}
+
+	for i := 0; i < 5; i++ {
+		cb.Call(ctx, op.execute)
+	}
+	if n := store.loads.Load(); n != 1 {
+		t.Errorf("Expected 1 load within the interval, got %d", n)
+	}
+
+	clock.Advance(time.Second)
+	cb.Call(ctx, op.execute)
+	if n := store.loads.Load(); n != 2 {
+		t.Errorf("Expected a second load after the interval, got %d", n)
+	}
+}
+
+func TestSharedStateManualOverridesStayLocal(t *testing.T) {
+	store := NewMemoryStateStore()
+	cb := NewCircuitBreaker(Config{Name: "search", StateStore: store})
+
+	cb.ForceOpen()
+	if _, ok, _ := store.Load("search"); ok {
+		t.Error("Expected ForceOpen not to be published")
+	}
+
+	cb.Reset()
+	state, ok, _ := store.Load("search")
+	if !ok || state.State != StateClosed {
+		t.Errorf("Expected Reset to publish Closed, got %+v, %v", state, ok)
+	}
+}
+
+func TestSharedStateStoreErrors(t *testing.T) {
+	cb := NewCircuitBreaker(Config{
+		StateStore: failingStore{},
+		ReadyToTrip: func(m Metrics) bool {
+			return m.ConsecutiveFailures >= 1
+		},
+	})
+	ctx := context.Background()
+
+	if _, err := cb.Call(ctx, (&mockOperation{}).execute); err != nil {
+		t.Fatalf("Expected calls to proceed without the store, got %v", err)
+	}
+	cb.Call(ctx, (&mockOperation{shouldFail: true}).execute)
+
+	m := cb.GetMetrics()
+	if m.StoreErrors != 2 {
+		t.Errorf("Expected a failed load and a failed publish, got %d store errors", m.StoreErrors)
+	}
+	if cb.GetState() != StateOpen {
+		t.Errorf("Expected the breaker to trip locally, got %v", cb.GetState())
+	}
+}
+
+func TestFileStateStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStateStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	if _, ok, err := store.Load("missing"); ok || err != nil {
+		t.Errorf("Expected no state for a missing breaker, got %v, %v", ok, err)
+	}
+
+	want := SharedState{State: StateOpen, OpenUntil: time.Unix(2000, 0).UTC(), UpdatedAt: time.Unix(1000, 0).UTC()}
+	if err := store.Store("api/v1 users", want); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	got, ok, err := store.Load("api/v1 users")
+	if err != nil || !ok || !got.OpenUntil.Equal(want.OpenUntil) || got.State != want.State {
+		t.Errorf("Expected %+v, got %+v, %v, %v", want, got, ok, err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected one file without temporary leftovers, got %d entries", len(entries))
+	}
+}
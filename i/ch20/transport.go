@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Transport errors
+var (
+	// ErrRetryAfter is returned, alongside ErrCircuitBreakerOpen, for requests
+	// to a host that asked clients to back off with a Retry-After header
+	ErrRetryAfter = errors.New("host asked to retry later")
+	// ErrNoResponse is returned when a breaker Fallback answers a request
+	// without an *http.Response
+	ErrNoResponse = errors.New("circuit breaker fallback returned no response")
+)
+
+// TransportConfig represents the configuration for a Transport
+type TransportConfig struct {
+	Base          http.RoundTripper              // Sends the requests, http.DefaultTransport by default
+	Registry      *Registry                      // Holds one breaker per host, NewRegistry(Config{}) by default
+	IsFailure     func(resp *http.Response) bool // Reports whether a response counts as a failure; 5xx and 429 by default
+	MaxRetryAfter time.Duration                  // Caps how long a Retry-After header blocks a host, 5 minutes by default
+	Clock         Clock                          // Time source for Retry-After; the system clock by default
+}
+
+// Transport is an http.RoundTripper that guards every host with its own
+// circuit breaker. Failing responses are still returned to the caller; they
+// are only counted against the host's breaker.
+type Transport struct {
+	config     TransportConfig
+	mutex      sync.Mutex
+	retryAfter map[string]time.Time // host -> time until which it asked to be left alone
+}
+
+// NewTransport creates a transport with the given configuration
+func NewTransport(config TransportConfig) *Transport {
+	if config.Base == nil {
+		config.Base = http.DefaultTransport
+	}
+	if config.Registry == nil {
+		config.Registry = NewRegistry(Config{})
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = func(resp *http.Response) bool {
+			return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		}
+	}
+	if config.MaxRetryAfter <= 0 {
+		config.MaxRetryAfter = 5 * time.Minute
+	}
+	if config.Clock == nil {
+		config.Clock = realClock{}
+	}
+	return &Transport{config: config, retryAfter: make(map[string]time.Time)}
+}
+
+// statusError marks a response classified as a failure
+type statusError struct {
+	status string
+}
+
+func (e *statusError) Error() string { return "unsuccessful response: " + e.status }
+
+// RoundTrip implements http.RoundTripper. Like any RoundTripper it closes the
+// request body, including when the request is rejected without being sent.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if until, ok := t.blockedUntil(host); ok {
+		closeBody(req)
+		return nil, fmt.Errorf("%w: %w: %s until %s", ErrCircuitBreakerOpen, ErrRetryAfter, host, until.Format(time.RFC3339))
+	}
+
+	var failed *http.Response
+	var sent atomic.Bool // Base owns the request body once it is called
+	result, err := t.config.Registry.Get(host).Execute(req.Context(), func(ctx context.Context) (interface{}, error) {
+		sent.Store(true)
+		resp, err := t.config.Base.RoundTrip(req.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		t.observeRetryAfter(host, resp)
+		if t.config.IsFailure(resp) {
+			failed = resp
+			return nil, &statusError{status: resp.Status}
+		}
+		return resp, nil
+	})
+
+	if !sent.Load() {
+		closeBody(req)
+	}
+
+	var se *statusError
+	if errors.As(err, &se) {
+		return failed, nil
+	}
+	if err != nil {
+		if failed != nil {
+			failed.Body.Close()
+		}
+		return nil, err
+	}
+
+	// A breaker Fallback may answer with a response of its own
+	resp, ok := result.(*http.Response)
+	if !ok || resp == nil {
+		if failed != nil {
+			failed.Body.Close()
+		}
+		return nil, ErrNoResponse
+	}
+	if failed != nil && failed != resp {
+		failed.Body.Close()
+	}
+	return resp, nil
+}
+
+// closeBody closes the body of a request that never reaches Base
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// Breaker returns the breaker guarding host
+func (t *Transport) Breaker(host string) CircuitBreaker {
+	return t.config.Registry.Get(host)
+}
+
+func (t *Transport) blockedUntil(host string) (time.Time, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	until, ok := t.retryAfter[host]
+	if !ok {
+		return time.Time{}, false
+	}
+	if !t.config.Clock.Now().Before(until) {
+		delete(t.retryAfter, host)
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// observeRetryAfter blocks host for the delay in a Retry-After header sent
+// with a 429 or 503 response
+func (t *Transport) observeRetryAfter(host string, resp *http.Response) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return
+	}
+	now := t.config.Clock.Now()
+	delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	if !ok || delay <= 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.retryAfter[host] = now.Add(min(delay, t.config.MaxRetryAfter))
+}
+
+// parseRetryAfter accepts both forms of the header: delay-seconds and an
+// HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, seconds >= 0
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date.Sub(now), true
+	}
+	return 0, false
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// statusServer answers every request with the status currently stored in code
+func statusServer(t *testing.T, code *atomic.Int32, header http.Header) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(int(code.Load()))
+		io.WriteString(w, "body")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// trackedBody records whether it was closed
+type trackedBody struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func newTrackedBody() *trackedBody { return &trackedBody{Reader: strings.NewReader("body")} }
+
+func (b *trackedBody) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+func newTestTransport(clock Clock) *Transport {
+	return NewTransport(TransportConfig{
+		Clock: clock,
+		Registry: NewRegistry(Config{
+			Clock:   clock,
+			Timeout: time.Minute,
+			ReadyToTrip: func(m Metrics) bool {
+				return m.ConsecutiveFailures >= 2
+			},
+		}),
+	})
+}
+
+func TestTransportStatusClassification(t *testing.T) {
+	var code atomic.Int32
+	srv := statusServer(t, &code, nil)
+	transport := newTestTransport(nil)
+	client := &http.Client{Transport: transport}
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	tests := []struct {
+		status  int
+		failure bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusNotFound, false},
+		{http.StatusBadRequest, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			transport.Breaker(host).Reset()
+			code.Store(int32(tt.status))
+
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Fatalf("Expected the response to reach the caller, got %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.status || string(body) != "body" {
+				t.Errorf("Expected %d with body, got %d %q", tt.status, resp.StatusCode, body)
+			}
+
+			m := transport.Breaker(host).GetMetrics()
+			if got := m.Failures == 1; got != tt.failure {
+				t.Errorf("Expected failure=%v, got metrics %+v", tt.failure, m)
+			}
+		})
+	}
+}
+
+func TestTransportOpensPerHost(t *testing.T) {
+	var bad, good atomic.Int32
+	bad.Store(http.StatusServiceUnavailable)
+	good.Store(http.StatusOK)
+	badSrv := statusServer(t, &bad, nil)
+	goodSrv := statusServer(t, &good, nil)
+
+	client := &http.Client{Transport: newTestTransport(nil)}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(badSrv.URL)
+		if err != nil {
+			t.Fatalf("Expected response, got %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	_, err := client.Get(badSrv.URL)
+	if !errors.Is(err, ErrCircuitBreakerOpen) {
+		t.Errorf("Expected ErrCircuitBreakerOpen for the failing host, got %v", err)
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Errorf("Expected http.Client to wrap the error in *url.Error, got %T", err)
+	}
+
+	resp, err := client.Get(goodSrv.URL)
+	if err != nil {
+		t.Fatalf("Expected the healthy host to be unaffected, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestTransportRetryAfter(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	var code atomic.Int32
+	code.Store(http.StatusTooManyRequests)
+	srv := statusServer(t, &code, http.Header{"Retry-After": {"30"}})
+
+	var requests atomic.Int32
+	transport := newTestTransport(clock)
+	base := transport.config.Base
+	transport.config.Base = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		return base.RoundTrip(req)
+	})
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Expected the 429 response, got %v", err)
+	}
+	resp.Body.Close()
+
+	_, err = client.Get(srv.URL)
+	if !errors.Is(err, ErrRetryAfter) || !errors.Is(err, ErrCircuitBreakerOpen) {
+		t.Errorf("Expected ErrRetryAfter and ErrCircuitBreakerOpen, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected the blocked request not to be sent, got %d requests", requests.Load())
+	}
+
+	code.Store(http.StatusOK)
+	clock.Advance(31 * time.Second)
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Expected requests to resume after Retry-After, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestTransportFallback(t *testing.T) {
+	var code atomic.Int32
+	code.Store(http.StatusInternalServerError)
+	srv := statusServer(t, &code, nil)
+
+	transport := NewTransport(TransportConfig{
+		Registry: NewRegistry(Config{
+			ReadyToTrip: func(m Metrics) bool { return m.ConsecutiveFailures >= 1 },
+			Fallback: func(ctx context.Context, err error) (interface{}, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("cached")),
+				}, nil
+			},
+		}),
+	})
+	client := &http.Client{Transport: transport}
+
+	resp, _ := client.Get(srv.URL)
+	resp.Body.Close()
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Expected fallback response, got %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "cached" {
+		t.Errorf("Expected cached body, got %q", body)
+	}
+}
+
+func TestTransportClosesBodies(t *testing.T) {
+	newRequest := func(body *trackedBody) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, "http://example.com/", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	respond := func(code int, body *trackedBody) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.Body.Close()
+			return &http.Response{StatusCode: code, Status: http.StatusText(code), Header: http.Header{}, Body: body}, nil
+		})
+	}
+
+	t.Run("Rejected By Breaker", func(t *testing.T) {
+		transport := newTestTransport(nil)
+		transport.config.Base = respond(http.StatusInternalServerError, newTrackedBody())
+		for i := 0; i < 2; i++ {
+			transport.RoundTrip(newRequest(newTrackedBody()))
+		}
+
+		body := newTrackedBody()
+		if _, err := transport.RoundTrip(newRequest(body)); !errors.Is(err, ErrCircuitBreakerOpen) {
+			t.Fatalf("Expected ErrCircuitBreakerOpen, got %v", err)
+		}
+		if !body.closed.Load() {
+			t.Error("Expected the request body of a rejected request to be closed")
+		}
+	})
+
+	t.Run("Blocked By Retry-After", func(t *testing.T) {
+		transport := newTestTransport(NewFakeClock(time.Now()))
+		transport.config.Base = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.Body.Close()
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": {"30"}},
+				Body:       newTrackedBody(),
+			}, nil
+		})
+		transport.RoundTrip(newRequest(newTrackedBody()))
+
+		body := newTrackedBody()
+		if _, err := transport.RoundTrip(newRequest(body)); !errors.Is(err, ErrRetryAfter) {
+			t.Fatalf("Expected ErrRetryAfter, got %v", err)
+		}
+		if !body.closed.Load() {
+			t.Error("Expected the request body of a blocked request to be closed")
+		}
+	})
+
+	t.Run("Fallback Error", func(t *testing.T) {
+		errNoCache := errors.New("no cached response")
+		transport := NewTransport(TransportConfig{
+			Registry: NewRegistry(Config{
+				FallbackOnFailure: true,
+				Fallback: func(ctx context.Context, err error) (interface{}, error) {
+					return nil, errNoCache
+				},
+			}),
+		})
+		failed := newTrackedBody()
+		transport.config.Base = respond(http.StatusInternalServerError, failed)
+
+		if _, err := transport.RoundTrip(newRequest(newTrackedBody())); !errors.Is(err, errNoCache) {
+			t.Fatalf("Expected the fallback error, got %v", err)
+		}
+		if !failed.closed.Load() {
+			t.Error("Expected the body of the failed response to be closed")
+		}
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"Mon, 01 Jan 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseRetryAfter(%q): expected %v, %v, got %v, %v", tt.value, tt.want, tt.ok, got, ok)
+		}
+	}
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrResultType is returned by the typed wrappers when a breaker Fallback
+// answers with a value that is not of the requested type
+var ErrResultType = errors.New("circuit breaker result has unexpected type")
+
+// Breaker is a typed wrapper around CircuitBreaker that returns results of
+// type T instead of interface{}.
+type Breaker[T any] struct {
+	cb CircuitBreaker
+}
+
+// NewBreaker creates a typed circuit breaker with the given configuration
+func NewBreaker[T any](config Config) *Breaker[T] {
+	return &Breaker[T]{cb: NewCircuitBreaker(config)}
+}
+
+// WrapBreaker returns a typed view of an existing circuit breaker. Several
+// typed views may share one breaker and therefore one state machine.
+func WrapBreaker[T any](cb CircuitBreaker) *Breaker[T] {
+	return &Breaker[T]{cb: cb}
+}
+
+// Call executes the given operation through the circuit breaker
+func (b *Breaker[T]) Call(ctx context.Context, operation func() (T, error)) (T, error) {
+	return Execute(ctx, b.cb, func(context.Context) (T, error) {
+		return operation()
+	})
+}
+
+// Execute runs a context-aware operation through the circuit breaker
+func (b *Breaker[T]) Execute(ctx context.Context, operation func(ctx context.Context) (T, error)) (T, error) {
+	return Execute(ctx, b.cb, operation)
+}
+
+// GetState returns the current state of the underlying circuit breaker
+func (b *Breaker[T]) GetState() State { return b.cb.GetState() }
+
+// GetMetrics returns the current metrics of the underlying circuit breaker
+func (b *Breaker[T]) GetMetrics() Metrics { return b.cb.GetMetrics() }
+
+// Unwrap returns the underlying untyped circuit breaker
+func (b *Breaker[T]) Unwrap() CircuitBreaker { return b.cb }
+
+// Execute runs a typed operation through any CircuitBreaker. On error the
+// zero value of T is returned, as it is when a Fallback answers with nil.
+func Execute[T any](ctx context.Context, cb CircuitBreaker, operation func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	result, err := cb.Execute(ctx, func(ctx context.Context) (interface{}, error) {
+		return operation(ctx)
+	})
+	if err != nil {
+		return zero, err
+	}
+	if result == nil {
+		return zero, nil
+	}
+	value, ok := result.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: got %T, want %T", ErrResultType, result, zero)
+	}
+	return value, nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import "time"
+
+// WindowType selects how the rolling window used by ReadyToTrip is bounded
+type WindowType int
+
+const (
+	// TimeWindow keeps the calls made during the last Config.Interval,
+	// split into Config.WindowBuckets buckets.
+	TimeWindow WindowType = iota
+	// CountWindow keeps the last Config.WindowSize calls.
+	CountWindow
+)
+
+// String returns the string representation of the window type
+func (w WindowType) String() string {
+	switch w {
+	case TimeWindow:
+		return "Time"
+	case CountWindow:
+		return "Count"
+	default:
+		return "Unknown"
+	}
+}
+
+// WindowMetrics summarizes the calls inside the rolling window
+type WindowMetrics struct {
+	Requests     int64
+	Failures     int64
+	SlowCalls    int64
+	FailureRate  float64 // Failures / Requests, 0 when there were no requests
+	SlowCallRate float64 // SlowCalls / Requests, 0 when there were no requests
+}
+
+// outcome is a single recorded call result
+type outcome struct {
+	failure bool
+	slow    bool
+}
+
+// rollingWindow accumulates call outcomes over a bounded range of recent history
+type rollingWindow interface {
+	record(now time.Time, o outcome)
+	snapshot(now time.Time) WindowMetrics
+	reset()
+}
+
+func newRollingWindow(config Config) rollingWindow {
+	if config.WindowType == CountWindow {
+		return &countWindow{outcomes: make([]outcome, config.WindowSize)}
+	}
+	return &timeWindow{
+		buckets: make([]windowBucket, config.WindowBuckets),
+		width:   config.Interval / time.Duration(config.WindowBuckets),
+		origin:  config.Clock.Now(),
+	}
+}
+
+// windowCounts holds running totals shared by both window kinds
+type windowCounts struct {
+	requests int64
+	failures int64
+	slow     int64
+}
+
+func (c *windowCounts) add(o outcome, sign int64) {
+	c.requests += sign
+	if o.failure {
+		c.failures += sign
+	}
+	if o.slow {
+		c.slow += sign
+	}
+}
+
+func (c *windowCounts) merge(other windowCounts) {
+	c.requests += other.requests
+	c.failures += other.failures
+	c.slow += other.slow
+}
+
+func (c windowCounts) metrics() WindowMetrics {
+	m := WindowMetrics{Requests: c.requests, Failures: c.failures, SlowCalls: c.slow}
+	if c.requests > 0 {
+		m.FailureRate = float64(c.failures) / float64(c.requests)
+		m.SlowCallRate = float64(c.slow) / float64(c.requests)
+	}
+	return m
+}
+
+// --- Count-based window ---
+
+// countWindow is a ring of the last len(outcomes) calls
+type countWindow struct {
+	outcomes []outcome
+	next     int
+	filled   int
+	totals   windowCounts
+}
+
+func (w *countWindow) record(_ time.Time, o outcome) {
+	if w.filled == len(w.outcomes) {
+		w.totals.add(w.outcomes[w.next], -1)
+	} else {
+		w.filled++
+	}
+	w.outcomes[w.next] = o
+	w.totals.add(o, 1)
+	w.next = (w.next + 1) % len(w.outcomes)
+}
+
+func (w *countWindow) snapshot(time.Time) WindowMetrics { return w.totals.metrics() }
+
+func (w *countWindow) reset() {
+	w.next, w.filled, w.totals = 0, 0, windowCounts{}
+}
+
+// --- Time-based window ---
+
+// windowBucket aggregates the calls of one bucket-width slice of time
+type windowBucket struct {
+	slot   int64 // time slot the counts belong to
+	counts windowCounts
+}
+
+// timeWindow is a ring of buckets indexed by time slots, so a bucket left
+// over from an earlier lap is recognised by its stale slot.
+type timeWindow struct {
+	buckets []windowBucket
+	width   time.Duration
+	origin  time.Time // slots are measured from here
+}
+
+// slot returns the time slot containing now, counted from the window's
+// origin and rounded down, so a clock set before the origin gets negative
+// slots rather than sharing slot 0.
+func (w *timeWindow) slot(now time.Time) int64 {
+	offset, width := int64(now.Sub(w.origin)), int64(w.width)
+	slot := offset / width
+	if offset%width < 0 {
+		slot--
+	}
+	return slot
+}
+
+// bucket returns the ring bucket a slot maps to
+func (w *timeWindow) bucket(slot int64) *windowBucket {
+	n := int64(len(w.buckets))
+	return &w.buckets[(slot%n+n)%n]
+}
+
+func (w *timeWindow) record(now time.Time, o outcome) {
+	slot := w.slot(now)
+	b := w.bucket(slot)
+	if b.slot != slot {
+		*b = windowBucket{slot: slot}
+	}
+	b.counts.add(o, 1)
+}
+
+func (w *timeWindow) snapshot(now time.Time) WindowMetrics {
+	current := w.slot(now)
+	var totals windowCounts
+	for _, b := range w.buckets {
+		if b.slot > current-int64(len(w.buckets)) && b.slot <= current {
+			totals.merge(b.counts)
+		}
+	}
+	return totals.metrics()
+}
+
+func (w *timeWindow) reset() {
+	clear(w.buckets)
+}
+
+// TripOnFailureRate returns a ReadyToTrip function that opens the breaker
+// once at least minRequests calls are in the window and the failure rate is
+// at or above percent (0-100).
+func TripOnFailureRate(percent float64, minRequests int64) func(Metrics) bool {
+	return func(m Metrics) bool {
+		return m.Window.Requests >= minRequests && m.Window.FailureRate*100 >= percent
+	}
+}
+
+// TripOnSlowCallRate returns a ReadyToTrip function that opens the breaker
+// once at least minRequests calls are in the window and the share of calls
+// slower than Config.SlowCallDurationThreshold is at or above percent (0-100).
+func TripOnSlowCallRate(percent float64, minRequests int64) func(Metrics) bool {
+	return func(m Metrics) bool {
+		return m.Window.Requests >= minRequests && m.Window.SlowCallRate*100 >= percent
+	}
+}
+
+// TripOnAny returns a ReadyToTrip function that opens the breaker as soon as
+// any of the given conditions does.
+func TripOnAny(conditions ...func(Metrics) bool) func(Metrics) bool {
+	return func(m Metrics) bool {
+		for _, trip := range conditions {
+			if trip(m) {
+				return true
+			}
+		}
+		return false
+	}
+}